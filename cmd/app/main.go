@@ -1,35 +1,122 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
 	"FinalTask/config"
-	"FinalTask/internal/models"
+	"FinalTask/internal/handler"
+	"FinalTask/internal/migrations"
+	"FinalTask/internal/notify"
 	"FinalTask/router"
+	"FinalTask/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
 func main() {
-	// Inisialisasi DB & JWT secret
-	config.InitDB()
-	config.InitSecret()
-
-	// AutoMigrate semua tabel
-	config.DB.AutoMigrate(
-		&models.User{},
-		&models.Alamat{},
-		&models.Toko{},
-		&models.Category{},
-		&models.Produk{},
-		&models.FotoProduk{},
-		&models.LogProduk{},
-		&models.Trx{},
-		&models.DetailTrx{},
-	)
-
-	app := fiber.New()
+	// Load & validasi konfigurasi sekali di awal
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	// Inisialisasi DB
+	db, err := config.OpenDB(cfg.DB)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	fmt.Printf("✅ Database connected (%s)\n", cfg.DB.Driver)
+
+	// Subcommand: app migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(db, os.Args[2:])
+		return
+	}
+
+	// AutoMigrate hanya untuk development, production pakai `app migrate up`
+	if cfg.DB.AutoMigrate {
+		log.Println("⚠️ DB_AUTO_MIGRATE aktif, menjalankan AutoMigrate (dev mode)")
+		if err := migrations.AutoMigrate(db); err != nil {
+			log.Fatal("❌ AutoMigrate gagal:", err)
+		}
+	} else if pending, err := migrations.Pending(db); err != nil {
+		log.Println("⚠️ Gagal membaca status migration:", err)
+	} else if len(pending) > 0 {
+		log.Printf("⚠️ %d migration belum dijalankan, jalankan `app migrate up`: %v", len(pending), pending)
+	}
+
+	// Kunci tanda tangan JWT (HS256 / RS256 / EdDSA) + kunci lama untuk rotasi
+	jwtKeys, err := utils.LoadJWTKeys(cfg.JWT)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	fmt.Printf("🔑 JWT %s\n", jwtKeys)
+
+	// Pengirim email (log / outbox / smtp) & SMS (log / outbox / webhook)
+	mailer, err := notify.NewMailer(cfg.Mail)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	sms, err := notify.NewSMSSender(cfg.SMS)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	// Tag validate yang salah ketik langsung ketahuan saat start
+	if err := handler.CheckRequestBodies(); err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	app := fiber.New(fiber.Config{
+		// Semua error dari handler & middleware dirender di satu tempat
+		ErrorHandler: handler.ErrorHandler,
+	})
+	// Panic di handler menjadi 500 lewat ErrorHandler, bukan mematikan server
+	app.Use(recover.New())
+
+	if len(cfg.App.CORSOrigins) > 0 {
+		app.Use(cors.New(cors.Config{
+			AllowOrigins: strings.Join(cfg.App.CORSOrigins, ","),
+		}))
+	}
 
 	// Routes
-	router.SetupRoutes(app)
+	router.SetupRoutes(app, cfg, db, jwtKeys, mailer, sms)
+
+	// Jalankan server di goroutine agar main bisa menunggu sinyal shutdown
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.Listen(cfg.App.ListenAddr())
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			log.Fatal("❌ Server berhenti:", err)
+		}
+	case <-ctx.Done():
+		log.Printf("🛑 Sinyal shutdown diterima, menunggu request berjalan selesai (maks %s)", cfg.App.ShutdownTimeout)
+		if err := app.ShutdownWithTimeout(cfg.App.ShutdownTimeout); err != nil {
+			log.Println("⚠️ Shutdown tidak bersih:", err)
+		}
+	}
 
-	app.Listen(":8000")
+	// Tutup pool koneksi setelah semua request selesai
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Println("⚠️ Gagal menutup koneksi database:", err)
+		}
+	}
+	log.Println("👋 Server berhenti")
 }
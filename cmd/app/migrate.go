@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"FinalTask/internal/migrations"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: app migrate up|down [steps]|status"
+
+// runMigrate handles `app migrate up|down [steps]|status`
+func runMigrate(db *gorm.DB, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(db)
+		for _, v := range applied {
+			fmt.Println("⬆️  applied", v)
+		}
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("✅ Schema sudah up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatal("❌ steps harus bilangan bulat positif")
+			}
+			steps = n
+		}
+		rolled, err := migrations.Down(db, steps)
+		for _, v := range rolled {
+			fmt.Println("⬇️  rolled back", v)
+		}
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		if len(rolled) == 0 {
+			fmt.Println("✅ Tidak ada migration untuk di-rollback")
+		}
+	case "status":
+		list, err := migrations.Statuses(db)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		for _, st := range list {
+			if st.Applied {
+				fmt.Printf("[x] %s  (%s)\n", st.Version, st.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("[ ] %s\n", st.Version)
+			}
+		}
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+	"FinalTask/internal/service"
+	"FinalTask/utils"
+
+	"gorm.io/gorm"
+)
+
+// createAdmin handles `ctl create-admin`. The admin gets a toko just like a
+// registered user so every account has the same shape.
+func (e *env) createAdmin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := fs.String("email", "", "admin email (required)")
+	password := fs.String("password", "", "admin password (required)")
+	nama := fs.String("nama", "Administrator", "display name")
+	noTelp := fs.String("no-telp", "", "phone number (required, unique)")
+	fs.Parse(args)
+
+	if *email == "" || *password == "" || *noTelp == "" {
+		return errors.New("-email, -password dan -no-telp wajib diisi")
+	}
+	if _, err := e.repos.User.FindByEmail(ctx, *email); err == nil {
+		return fmt.Errorf("email %s sudah terdaftar, gunakan `ctl promote`", *email)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	hashed, err := utils.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	user := &models.User{
+		Nama:     *nama,
+		Email:    *email,
+		NoTelp:   *noTelp,
+		Password: hashed,
+		IsAdmin:  true,
+		// Dibuat operator, kontak dianggap sudah terverifikasi
+		EmailVerifiedAt: &now,
+		PhoneVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	err = e.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.User.Create(ctx, user); err != nil {
+			return err
+		}
+		role, err := repos.Role.FindByName(ctx, models.RoleSuperAdmin)
+		if err != nil {
+			return fmt.Errorf("role %s belum ada, jalankan migrasi dulu: %w", models.RoleSuperAdmin, err)
+		}
+		if err := repos.Role.Assign(ctx, user.ID, role.ID, now); err != nil {
+			return err
+		}
+		return repos.Store.Create(ctx, &models.Toko{
+			IDUser:    user.ID,
+			NamaToko:  *nama + "'s Store",
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Admin %s dibuat (id=%d)\n", user.Email, user.ID)
+	return nil
+}
+
+// setRole handles `ctl grant` / `ctl revoke`, and `ctl promote` / `ctl demote`
+// which are the same with the super-admin role. It goes through RoleService
+// so is_admin, the last-super-admin guard and session revocation match the API.
+func (e *env) setRole(ctx context.Context, args []string, defaultRole string, assign bool) error {
+	fs := flag.NewFlagSet("grant/revoke", flag.ExitOnError)
+	email := fs.String("email", "", "user email (required)")
+	roleName := fs.String("role", defaultRole, "role name")
+	fs.Parse(args)
+
+	if *email == "" || *roleName == "" {
+		return errors.New("-email dan -role wajib diisi")
+	}
+	user, err := e.repos.User.FindByEmail(ctx, *email)
+	if err != nil {
+		return fmt.Errorf("user %s tidak ditemukan: %w", *email, err)
+	}
+
+	roles := service.NewRoleService(e.uow, e.repos.Role)
+	if !assign {
+		if err := roles.RemoveRole(ctx, user.ID, *roleName); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Role %s dicabut dari %s\n", *roleName, user.Email)
+		return nil
+	}
+	if err := roles.AssignRole(ctx, user.ID, service.AssignRoleRequest{Role: *roleName}); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Role %s ditambahkan ke %s\n", *roleName, user.Email)
+	return nil
+}
+
+// resetPassword handles `ctl reset-password`
+func (e *env) resetPassword(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	email := fs.String("email", "", "user email (required)")
+	password := fs.String("password", "", "new password (random if empty)")
+	fs.Parse(args)
+
+	if *email == "" {
+		return errors.New("-email wajib diisi")
+	}
+	user, err := e.repos.User.FindByEmail(ctx, *email)
+	if err != nil {
+		return fmt.Errorf("user %s tidak ditemukan: %w", *email, err)
+	}
+
+	plain := *password
+	generated := plain == ""
+	if generated {
+		buf := make([]byte, 12)
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		plain = base64.RawURLEncoding.EncodeToString(buf)
+	}
+	hashed, err := utils.HashPassword(plain)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	user.UpdatedAt = time.Now()
+	// Sama seperti reset lewat API: semua sesi user dicabut
+	err = e.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.User.Update(ctx, user); err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := repos.Session.RevokeByUserID(ctx, user.ID, now); err != nil {
+			return err
+		}
+		return repos.RefreshToken.RevokeByUserID(ctx, user.ID, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	if generated {
+		fmt.Printf("✅ Password %s direset: %s\n", user.Email, plain)
+	} else {
+		fmt.Printf("✅ Password %s direset\n", user.Email)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"FinalTask/config"
+	"FinalTask/utils"
+)
+
+// genJWTKey handles `ctl gen-jwt-key`. It needs no database: it writes a new
+// private key (mode 0600) and its public half next to it as <out>.pub.
+func genJWTKey(args []string) error {
+	fs := flag.NewFlagSet("gen-jwt-key", flag.ExitOnError)
+	alg := fs.String("alg", config.JWTAlgEdDSA, "RS256 or EdDSA")
+	out := fs.String("out", "", "private key path, e.g. keys/jwt-2026-10.pem (required)")
+	fs.Parse(args)
+
+	if *out == "" {
+		return errors.New("-out wajib diisi")
+	}
+	if _, err := os.Stat(*out); err == nil {
+		return fmt.Errorf("%s sudah ada, pilih nama lain", *out)
+	}
+	privatePEM, publicPEM, err := utils.GenerateJWTKeyPEM(*alg)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, privatePEM, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out+".pub", publicPEM, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Kunci %s ditulis ke %s (publik: %s.pub)\n", *alg, *out, *out)
+	fmt.Println("   Rotasi: pindahkan kunci lama ke JWT_PUBLIC_KEY_FILES, lalu set JWT_PRIVATE_KEY_FILE ke kunci baru")
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"FinalTask/config"
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const usage = `usage: ctl <command> [flags]
+
+commands:
+  create-admin    -email -password -nama -no-telp   create an admin user (+ toko)
+  promote         -email                            grant the super-admin role
+  demote          -email                            revoke the super-admin role
+  grant           -email -role                      grant a role (e.g. support)
+  revoke          -email -role                      revoke a role (logs the user out)
+  reset-password  -email [-password]                set a new password (random if empty)
+  seed                                              insert deterministic demo data
+  gen-jwt-key     -out [-alg RS256|EdDSA]           write a new JWT signing key pair (no DB needed)`
+
+// env is what every subcommand needs: an open DB and repositories on it
+type env struct {
+	db    *gorm.DB
+	repos *repository.Repositories
+	uow   repository.UnitOfWork
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	// Tidak butuh konfigurasi maupun database
+	if os.Args[1] == "gen-jwt-key" {
+		if err := genJWTKey(os.Args[2:]); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	db, err := config.OpenDB(cfg.DB)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	e := &env{
+		db:    db,
+		repos: repository.NewRepositories(db),
+		uow:   repository.NewUnitOfWork(db),
+	}
+
+	ctx := context.Background()
+	args := os.Args[2:]
+
+	switch os.Args[1] {
+	case "create-admin":
+		err = e.createAdmin(ctx, args)
+	case "promote":
+		err = e.setRole(ctx, args, models.RoleSuperAdmin, true)
+	case "demote":
+		err = e.setRole(ctx, args, models.RoleSuperAdmin, false)
+	case "grant":
+		err = e.setRole(ctx, args, "", true)
+	case "revoke":
+		err = e.setRole(ctx, args, "", false)
+	case "reset-password":
+		err = e.resetPassword(ctx, args)
+	case "seed":
+		err = e.seed(ctx)
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+	"FinalTask/utils"
+
+	"gorm.io/gorm"
+)
+
+// Demo data is fixed (names, prices, invoice codes and timestamps) so every
+// QA environment seeded from an empty database ends up with identical rows.
+var seedBaseTime = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+const seedPassword = "password123"
+
+type seedUser struct {
+	Nama   string
+	Email  string
+	NoTelp string
+	Role   string // kosong = user biasa
+	Toko   string
+}
+
+var seedUsers = []seedUser{
+	{Nama: "Admin", Email: "admin@example.com", NoTelp: "080000000000", Role: models.RoleSuperAdmin, Toko: "Admin's Store"},
+	{Nama: "Budi Santoso", Email: "budi@example.com", NoTelp: "081200000001", Toko: "Toko Budi Elektronik"},
+	{Nama: "Siti Aminah", Email: "siti@example.com", NoTelp: "081200000002", Toko: "Siti Fashion"},
+	{Nama: "Andi Pratama", Email: "andi@example.com", NoTelp: "081200000003", Toko: "Andi's Store"},
+	{Nama: "Customer Support", Email: "support@example.com", NoTelp: "081200000009", Role: models.RoleSupport, Toko: "Support's Store"},
+}
+
+var seedCategories = []string{"Elektronik", "Fashion", "Kesehatan & Kecantikan", "Makanan & Minuman"}
+
+type seedProduct struct {
+	Owner         string // email pemilik toko
+	Category      string
+	NamaProduk    string
+	Slug          string
+	HargaReseller models.Money
+	HargaKonsumen models.Money
+	Stok          int
+	Deskripsi     string
+}
+
+var seedProducts = []seedProduct{
+	{"budi@example.com", "Elektronik", "Earphone Bluetooth", "earphone-bluetooth", 120000, 150000, 50, "Earphone nirkabel dengan baterai 20 jam."},
+	{"budi@example.com", "Elektronik", "Power Bank 10000mAh", "power-bank-10000mah", 160000, 199000, 30, "Power bank fast charging 18W."},
+	{"budi@example.com", "Elektronik", "Kabel USB-C 1m", "kabel-usb-c-1m", 20000, 35000, 200, "Kabel data USB-C braided."},
+	{"siti@example.com", "Fashion", "Kemeja Batik Pria", "kemeja-batik-pria", 110000, 145000, 40, "Kemeja batik katun lengan pendek."},
+	{"siti@example.com", "Fashion", "Hijab Voal Polos", "hijab-voal-polos", 35000, 55000, 120, "Hijab voal premium berbagai warna."},
+	{"siti@example.com", "Kesehatan & Kecantikan", "Shampoo Anti Ketombe", "shampoo-anti-ketombe", 25000, 32000, 80, "Shampoo anti ketombe 340ml."},
+	{"andi@example.com", "Makanan & Minuman", "Kopi Arabika Gayo 250g", "kopi-arabika-gayo-250g", 70000, 95000, 60, "Biji kopi arabika Gayo roasting medium."},
+}
+
+type seedItem struct {
+	Slug      string
+	Kuantitas int
+}
+
+type seedTrx struct {
+	Buyer       string
+	KodeInvoice string
+	MethodBayar string
+	Items       []seedItem
+}
+
+// Satu transaksi per toko, sama seperti checkout yang memecah keranjang per toko
+var seedTransactions = []seedTrx{
+	{"andi@example.com", "INV-SEED-0001", "transfer", []seedItem{{"earphone-bluetooth", 1}, {"kabel-usb-c-1m", 2}}},
+	{"andi@example.com", "INV-SEED-0002", "cod", []seedItem{{"kemeja-batik-pria", 2}}},
+	{"budi@example.com", "INV-SEED-0003", "transfer", []seedItem{{"kopi-arabika-gayo-250g", 3}}},
+	{"budi@example.com", "INV-SEED-0004", "transfer", []seedItem{{"hijab-voal-polos", 1}}},
+}
+
+// seed handles `ctl seed`. It runs in one transaction and is a no-op when
+// the demo users already exist.
+func (e *env) seed(ctx context.Context) error {
+	if _, err := e.repos.User.FindByEmail(ctx, seedUsers[0].Email); err == nil {
+		fmt.Println("✅ Data demo sudah ada, seed dilewati")
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	hashed, err := utils.HashPassword(seedPassword)
+	if err != nil {
+		return err
+	}
+
+	// tick memberi timestamp berurutan yang sama di setiap run
+	tick := 0
+	at := func() time.Time {
+		tick++
+		return seedBaseTime.Add(time.Duration(tick) * time.Minute)
+	}
+
+	err = e.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		users := map[string]*models.User{}
+		stores := map[string]*models.Toko{}
+		for _, su := range seedUsers {
+			ts := at()
+			user := &models.User{
+				Nama:     su.Nama,
+				Email:    su.Email,
+				NoTelp:   su.NoTelp,
+				Password: hashed,
+				IsAdmin:  su.Role == models.RoleSuperAdmin,
+				// Akun demo langsung terverifikasi agar bisa checkout & berjualan
+				EmailVerifiedAt: &ts,
+				PhoneVerifiedAt: &ts,
+				CreatedAt:       ts,
+				UpdatedAt:       ts,
+			}
+			if err := repos.User.Create(ctx, user); err != nil {
+				return err
+			}
+			if su.Role != "" {
+				role, err := repos.Role.FindByName(ctx, su.Role)
+				if err != nil {
+					return fmt.Errorf("role %s belum ada, jalankan migrasi dulu: %w", su.Role, err)
+				}
+				if err := repos.Role.Assign(ctx, user.ID, role.ID, ts); err != nil {
+					return err
+				}
+			}
+			store := &models.Toko{IDUser: user.ID, NamaToko: su.Toko, CreatedAt: ts, UpdatedAt: ts}
+			if err := repos.Store.Create(ctx, store); err != nil {
+				return err
+			}
+			users[su.Email] = user
+			stores[su.Email] = store
+		}
+
+		categories := map[string]*models.Category{}
+		for _, name := range seedCategories {
+			ts := at()
+			cat := &models.Category{NamaCategory: name, CreatedAt: ts, UpdatedAt: ts}
+			if err := repos.Category.Create(ctx, cat); err != nil {
+				return err
+			}
+			categories[name] = cat
+		}
+
+		products := map[string]*models.Produk{}
+		logs := map[string]*models.LogProduk{}
+		for _, sp := range seedProducts {
+			ts := at()
+			prod := &models.Produk{
+				NamaProduk:    sp.NamaProduk,
+				Slug:          sp.Slug,
+				HargaReseller: sp.HargaReseller,
+				HargaKonsumen: sp.HargaKonsumen,
+				Stok:          sp.Stok,
+				Deskripsi:     sp.Deskripsi,
+				IDToko:        stores[sp.Owner].ID,
+				IDCategory:    categories[sp.Category].ID,
+				CreatedAt:     ts,
+				UpdatedAt:     ts,
+			}
+			if err := repos.Product.Create(ctx, prod); err != nil {
+				return err
+			}
+			logEntry := &models.LogProduk{
+				IDProduk:      prod.ID,
+				Versi:         1,
+				NamaProduk:    prod.NamaProduk,
+				Slug:          prod.Slug,
+				HargaReseller: prod.HargaReseller,
+				HargaKonsumen: prod.HargaKonsumen,
+				Deskripsi:     prod.Deskripsi,
+				IDToko:        prod.IDToko,
+				IDCategory:    prod.IDCategory,
+				StokAwal:      prod.Stok,
+				CreatedAt:     ts,
+				UpdatedAt:     ts,
+			}
+			if err := repos.Product.CreateLog(ctx, logEntry); err != nil {
+				return err
+			}
+			if err := repos.Product.SetCurrentLog(ctx, prod.ID, logEntry.ID); err != nil {
+				return err
+			}
+			products[sp.Slug] = prod
+			logs[sp.Slug] = logEntry
+		}
+
+		for _, st := range seedTransactions {
+			ts := at()
+			buyer := users[st.Buyer]
+			addr := &models.Alamat{
+				IDUser:       buyer.ID,
+				JudulAlamat:  "Rumah " + st.KodeInvoice,
+				NamaPenerima: buyer.Nama,
+				NoTelp:       buyer.NoTelp,
+				DetailAlamat: "Jl. Contoh No. 1, Jakarta",
+				CreatedAt:    ts,
+				UpdatedAt:    ts,
+			}
+			if err := repos.Address.Create(ctx, addr); err != nil {
+				return err
+			}
+			trx := &models.Trx{
+				IDUser:           buyer.ID,
+				AlamatPengiriman: addr.ID,
+				MethodBayar:      st.MethodBayar,
+				Status:           models.OrderPendingPayment,
+				KodeInvoice:      st.KodeInvoice,
+				CreatedAt:        ts,
+				UpdatedAt:        ts,
+			}
+			if err := repos.Transaction.Create(ctx, trx); err != nil {
+				return err
+			}
+			if err := repos.Transaction.CreateEvent(ctx, &models.OrderEvent{
+				IDTrx:     trx.ID,
+				ToStatus:  models.OrderPendingPayment,
+				IDActor:   &buyer.ID,
+				ActorRole: models.ActorBuyer,
+				CreatedAt: ts,
+			}); err != nil {
+				return err
+			}
+			var total models.Money
+			for _, item := range st.Items {
+				logEntry := logs[item.Slug]
+				detail := &models.DetailTrx{
+					IDTrx:       trx.ID,
+					IDLogProduk: logEntry.ID,
+					IDToko:      logEntry.IDToko,
+					Kuantitas:   item.Kuantitas,
+					HargaTotal:  logEntry.HargaKonsumen.Mul(item.Kuantitas),
+					CreatedAt:   ts,
+					UpdatedAt:   ts,
+				}
+				if err := repos.Transaction.CreateDetail(ctx, detail); err != nil {
+					return err
+				}
+				total += detail.HargaTotal
+				ok, err := repos.Product.DecrementStock(ctx, products[item.Slug].ID, item.Kuantitas)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("stok %s tidak cukup untuk seed %s", item.Slug, st.KodeInvoice)
+				}
+			}
+			trx.HargaTotal = total
+			if err := repos.Transaction.Update(ctx, trx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Seed selesai: %d user, %d kategori, %d produk, %d transaksi (password: %s)\n",
+		len(seedUsers), len(seedCategories), len(seedProducts), len(seedTransactions), seedPassword)
+	return nil
+}
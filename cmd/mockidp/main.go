@@ -0,0 +1,245 @@
+// Command mockidp is a tiny OpenID Connect provider for local testing of
+// the "Login with ..." flow. It approves every login without a UI: the
+// identity comes from the flags, or from email/name/sub/email_verified
+// query parameters appended to the authorization URL.
+//
+//	go run ./cmd/mockidp -addr :9000 -client-id finaltask -client-secret dev-secret
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const kid = "mock-1"
+
+// grant is an issued authorization code waiting for the token request
+type grant struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims
+	expiresAt     time.Time
+}
+
+type idp struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	key          *rsa.PrivateKey
+
+	defaults url.Values
+
+	mu    sync.Mutex
+	codes map[string]*grant
+}
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL (must match the app config)")
+	clientID := flag.String("client-id", "finaltask", "accepted client_id")
+	clientSecret := flag.String("client-secret", "dev-secret", "accepted client secret")
+	email := flag.String("email", "mock.user@example.com", "default email claim")
+	name := flag.String("name", "Mock User", "default name claim")
+	verified := flag.Bool("email-verified", true, "default email_verified claim")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	p := &idp{
+		issuer:       *issuer,
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		key:          key,
+		defaults: url.Values{
+			"email":          {*email},
+			"name":           {*name},
+			"email_verified": {strconv.FormatBool(*verified)},
+		},
+		codes: map[string]*grant{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+
+	log.Printf("🧪 mock OIDC provider %s di %s (client_id=%s)", *issuer, *addr, *clientID)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (p *idp) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *idp) jwks(w http.ResponseWriter, _ *http.Request) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA", "use": "sig", "alg": "RS256", "kid": kid,
+			"n": b64(pub.N.Bytes()), "e": b64(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize langsung menyetujui login dan kembali ke redirect_uri dengan code
+func (p *idp) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if q.Get("client_id") != p.clientID || redirectURI == "" {
+		http.Error(w, "unknown client_id or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+	back, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := back.Query()
+	params.Set("state", q.Get("state"))
+	if denied := q.Get("deny"); denied != "" {
+		params.Set("error", "access_denied")
+		back.RawQuery = params.Encode()
+		http.Redirect(w, r, back.String(), http.StatusFound)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE S256 required", http.StatusBadRequest)
+		return
+	}
+
+	value := func(k string) string {
+		if v := q.Get(k); v != "" {
+			return v
+		}
+		return p.defaults.Get(k)
+	}
+	email := value("email")
+	sub := q.Get("sub")
+	if sub == "" {
+		sum := sha256.Sum256([]byte(email))
+		sub = base64.RawURLEncoding.EncodeToString(sum[:12])
+	}
+	verified, _ := strconv.ParseBool(value("email_verified"))
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = &grant{
+		clientID:      p.clientID,
+		redirectURI:   redirectURI,
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		claims: jwt.MapClaims{
+			"sub":            sub,
+			"email":          email,
+			"email_verified": verified,
+			"name":           value("name"),
+		},
+		expiresAt: time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	params.Set("code", code)
+	back.RawQuery = params.Encode()
+	log.Printf("🔐 login %s (sub=%s) disetujui", email, sub)
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+// token menukar code (sekali pakai) dengan id_token setelah memeriksa
+// client secret, redirect_uri dan PKCE verifier
+func (p *idp) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != p.clientID || secret != p.clientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	g := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case g == nil || time.Now().After(g.expiresAt) || g.redirectURI != r.PostForm.Get("redirect_uri"):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != g.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verifier mismatch"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.issuer,
+		"aud":   g.clientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": g.nonce,
+	}
+	for k, v := range g.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
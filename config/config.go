@@ -1,37 +1,588 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
+	"gopkg.in/yaml.v3"
 )
 
-var DB *gorm.DB
+// Config holds every runtime setting of the application.
+// It is loaded once at startup by Load and passed into constructors.
+type Config struct {
+	App    AppConfig    `yaml:"app"`
+	DB     DBConfig     `yaml:"db"`
+	JWT    JWTConfig    `yaml:"jwt"`
+	Auth   AuthConfig   `yaml:"auth"`
+	Mail   MailConfig   `yaml:"mail"`
+	SMS    SMSConfig    `yaml:"sms"`
+	Region RegionConfig `yaml:"region"`
+	OIDC   OIDCConfig   `yaml:"oidc"`
+}
+
+type AppConfig struct {
+	Port            string        `yaml:"port"`
+	UploadDir       string        `yaml:"upload_dir"`
+	CORSOrigins     []string      `yaml:"cors_origins"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type DBConfig struct {
+	Driver          string        `yaml:"driver"`
+	DSN             string        `yaml:"dsn"`
+	Host            string        `yaml:"host"`
+	Port            string        `yaml:"port"`
+	User            string        `yaml:"user"`
+	Pass            string        `yaml:"pass"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"sslmode"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	AutoMigrate     bool          `yaml:"auto_migrate"`
+}
+
+// JWTConfig selects how access tokens are signed. HS256 signs with Secret;
+// RS256 and EdDSA sign with the PEM private key in PrivateKeyFile and publish
+// the public half (plus PublicKeyFiles, the keys being rotated out) as JWKS.
+type JWTConfig struct {
+	Algorithm      string        `yaml:"algorithm"`
+	Secret         string        `yaml:"secret"`
+	PrivateKeyFile string        `yaml:"private_key_file"`
+	PublicKeyFiles []string      `yaml:"public_key_files"`
+	Issuer         string        `yaml:"issuer"`
+	Audience       string        `yaml:"audience"`
+	AccessTTL      time.Duration `yaml:"access_ttl"`
+	RefreshTTL     time.Duration `yaml:"refresh_ttl"`
+}
+
+// Algoritma tanda tangan JWT yang didukung
+const (
+	JWTAlgHS256 = "HS256"
+	JWTAlgRS256 = "RS256"
+	JWTAlgEdDSA = "EdDSA"
+)
+
+type AuthConfig struct {
+	PasswordResetTTL time.Duration `yaml:"password_reset_ttl"`
+	// PasswordResetURL is the frontend page that receives ?token=...
+	PasswordResetURL string `yaml:"password_reset_url"`
+
+	// Kode verifikasi (OTP) email & nomor telepon
+	OTPTTL            time.Duration `yaml:"otp_ttl"`
+	OTPResendInterval time.Duration `yaml:"otp_resend_interval"`
+	OTPMaxPerHour     int           `yaml:"otp_max_per_hour"`
+	OTPMaxAttempts    int           `yaml:"otp_max_attempts"`
+
+	// Proteksi brute-force login: backoff eksponensial per akun,
+	// akun dikunci setelah LoginMaxAttempts gagal berturut-turut,
+	// IP diblokir setelah LoginIPMaxFailures gagal dalam LoginIPWindow
+	LoginMaxAttempts   int           `yaml:"login_max_attempts"`
+	LoginLockout       time.Duration `yaml:"login_lockout"`
+	LoginBackoffBase   time.Duration `yaml:"login_backoff_base"`
+	LoginBackoffMax    time.Duration `yaml:"login_backoff_max"`
+	LoginIPMaxFailures int           `yaml:"login_ip_max_failures"`
+	LoginIPWindow      time.Duration `yaml:"login_ip_window"`
+
+	// 2FA (TOTP): nama issuer di aplikasi authenticator, masa berlaku
+	// challenge token setelah password benar dan batas salah kode per challenge
+	TOTPIssuer            string        `yaml:"totp_issuer"`
+	TwoFactorChallengeTTL time.Duration `yaml:"two_factor_challenge_ttl"`
+	TwoFactorMaxAttempts  int           `yaml:"two_factor_max_attempts"`
+}
+
+// MailConfig selects the mail sender: "log" prints messages, "outbox"
+// writes them as files for offline testing, "smtp" really sends them
+type MailConfig struct {
+	Driver    string `yaml:"driver"`
+	From      string `yaml:"from"`
+	OutboxDir string `yaml:"outbox_dir"`
+	SMTPHost  string `yaml:"smtp_host"`
+	SMTPPort  string `yaml:"smtp_port"`
+	SMTPUser  string `yaml:"smtp_user"`
+	SMTPPass  string `yaml:"smtp_pass"`
+}
 
-func InitDB() {
-	// Load .env
-	if err := godotenv.Load(); err != nil {
+// SMSConfig selects the SMS sender: "log", "outbox" (files for offline
+// testing) or "webhook" (JSON POST to an SMS gateway)
+type SMSConfig struct {
+	Driver     string `yaml:"driver"`
+	OutboxDir  string `yaml:"outbox_dir"`
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+type RegionConfig struct {
+	BaseURL string `yaml:"base_url"`
+}
+
+// OIDCConfig lists the OpenID Connect providers offered as "Login with ...".
+// StateTTL is how long the user may take at the provider before the
+// callback is rejected.
+type OIDCConfig struct {
+	StateTTL  time.Duration        `yaml:"state_ttl"`
+	Providers []OIDCProviderConfig `yaml:"providers"`
+}
+
+// OIDCProviderConfig is one provider. Endpoints and signing keys are
+// discovered from Issuer + "/.well-known/openid-configuration".
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name"` // dipakai di URL: /auth/oidc/:name/login
+	DisplayName  string   `yaml:"display_name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"` // harus menunjuk ke /api/v1/auth/oidc/:name/callback
+	Scopes       []string `yaml:"scopes"`
+}
+
+// Default returns the settings used when nothing else is configured
+func Default() Config {
+	return Config{
+		App: AppConfig{
+			Port:            "8000",
+			UploadDir:       "uploads",
+			ShutdownTimeout: 15 * time.Second,
+		},
+		DB: DBConfig{
+			Driver:          DriverMySQL,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		JWT: JWTConfig{
+			Algorithm:  JWTAlgHS256,
+			Issuer:     "finaltask",
+			Audience:   "finaltask-api",
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		Auth: AuthConfig{
+			PasswordResetTTL:  30 * time.Minute,
+			PasswordResetURL:  "http://localhost:3000/reset-password",
+			OTPTTL:            10 * time.Minute,
+			OTPResendInterval: time.Minute,
+			OTPMaxPerHour:     5,
+			OTPMaxAttempts:    5,
+
+			LoginMaxAttempts:   10,
+			LoginLockout:       15 * time.Minute,
+			LoginBackoffBase:   time.Second,
+			LoginBackoffMax:    time.Minute,
+			LoginIPMaxFailures: 50,
+			LoginIPWindow:      15 * time.Minute,
+
+			TOTPIssuer:            "FinalTask",
+			TwoFactorChallengeTTL: 5 * time.Minute,
+			TwoFactorMaxAttempts:  5,
+		},
+		Mail: MailConfig{
+			Driver:    "outbox",
+			From:      "no-reply@finaltask.local",
+			OutboxDir: "outbox",
+			SMTPPort:  "587",
+		},
+		SMS: SMSConfig{
+			Driver:    "outbox",
+			OutboxDir: "outbox",
+		},
+		Region: RegionConfig{
+			BaseURL: "https://www.emsifa.com/api-wilayah-indonesia/api",
+		},
+		OIDC: OIDCConfig{
+			StateTTL: 10 * time.Minute,
+		},
+	}
+}
+
+// Load builds the Config in layers, each overriding the one before:
+// defaults, the .env file, the optional YAML file named by CONFIG_FILE, then
+// the process environment. Empty values are skipped in both env layers, so
+// `KEY=` never clears a setting from a lower layer.
+func Load() (*Config, error) {
+	dotenv, err := godotenv.Read()
+	if err != nil {
 		log.Println("⚠️ .env file tidak ditemukan, gunakan environment variables sistem")
 	}
+	fileEnv := envSource(func(key string) (string, bool) {
+		v, ok := dotenv[key]
+		return v, ok
+	})
+
+	cfg := Default()
+	if err := cfg.applyEnv(fileEnv); err != nil {
+		return nil, fmt.Errorf(".env: %w", err)
+	}
+
+	path, ok := envSource(os.LookupEnv).get("CONFIG_FILE")
+	if !ok {
+		path, _ = fileEnv.get("CONFIG_FILE")
+	}
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(raw, &cfg); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	cfg.DB.Driver = strings.ToLower(cfg.DB.Driver)
+	if cfg.DB.Driver == "postgresql" {
+		cfg.DB.Driver = DriverPostgres
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) applyEnv(env envSource) error {
+	var errs []error
+
+	env.setString(&c.App.Port, "APP_PORT")
+	env.setString(&c.App.UploadDir, "UPLOAD_DIR")
+	if v, ok := env.get("CORS_ORIGINS"); ok {
+		c.App.CORSOrigins = splitList(v)
+	}
+	errs = append(errs, env.setDuration(&c.App.ShutdownTimeout, "SHUTDOWN_TIMEOUT"))
+
+	env.setString(&c.DB.Driver, "DB_DRIVER")
+	env.setString(&c.DB.DSN, "DB_DSN")
+	env.setString(&c.DB.Host, "DB_HOST")
+	env.setString(&c.DB.Port, "DB_PORT")
+	env.setString(&c.DB.User, "DB_USER")
+	env.setString(&c.DB.Pass, "DB_PASS")
+	env.setString(&c.DB.Name, "DB_NAME")
+	env.setString(&c.DB.SSLMode, "DB_SSLMODE")
+	errs = append(errs,
+		env.setInt(&c.DB.MaxOpenConns, "DB_MAX_OPEN_CONNS"),
+		env.setInt(&c.DB.MaxIdleConns, "DB_MAX_IDLE_CONNS"),
+		env.setDuration(&c.DB.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"),
+		env.setBool(&c.DB.AutoMigrate, "DB_AUTO_MIGRATE"),
+	)
+
+	env.setString(&c.JWT.Algorithm, "JWT_ALGORITHM")
+	env.setString(&c.JWT.Secret, "JWT_SECRET")
+	env.setString(&c.JWT.PrivateKeyFile, "JWT_PRIVATE_KEY_FILE")
+	if v, ok := env.get("JWT_PUBLIC_KEY_FILES"); ok {
+		c.JWT.PublicKeyFiles = splitList(v)
+	}
+	env.setString(&c.JWT.Issuer, "JWT_ISSUER")
+	env.setString(&c.JWT.Audience, "JWT_AUDIENCE")
+	errs = append(errs,
+		env.setDuration(&c.JWT.AccessTTL, "JWT_ACCESS_TTL"),
+		env.setDuration(&c.JWT.RefreshTTL, "JWT_REFRESH_TTL"),
+	)
+
+	errs = append(errs, env.setDuration(&c.Auth.PasswordResetTTL, "PASSWORD_RESET_TTL"))
+	env.setString(&c.Auth.PasswordResetURL, "PASSWORD_RESET_URL")
+	errs = append(errs,
+		env.setDuration(&c.Auth.OTPTTL, "OTP_TTL"),
+		env.setDuration(&c.Auth.OTPResendInterval, "OTP_RESEND_INTERVAL"),
+		env.setInt(&c.Auth.OTPMaxPerHour, "OTP_MAX_PER_HOUR"),
+		env.setInt(&c.Auth.OTPMaxAttempts, "OTP_MAX_ATTEMPTS"),
+		env.setInt(&c.Auth.LoginMaxAttempts, "LOGIN_MAX_ATTEMPTS"),
+		env.setDuration(&c.Auth.LoginLockout, "LOGIN_LOCKOUT"),
+		env.setDuration(&c.Auth.LoginBackoffBase, "LOGIN_BACKOFF_BASE"),
+		env.setDuration(&c.Auth.LoginBackoffMax, "LOGIN_BACKOFF_MAX"),
+		env.setInt(&c.Auth.LoginIPMaxFailures, "LOGIN_IP_MAX_FAILURES"),
+		env.setDuration(&c.Auth.LoginIPWindow, "LOGIN_IP_WINDOW"),
+		env.setDuration(&c.Auth.TwoFactorChallengeTTL, "TWO_FACTOR_CHALLENGE_TTL"),
+		env.setInt(&c.Auth.TwoFactorMaxAttempts, "TWO_FACTOR_MAX_ATTEMPTS"),
+	)
+	env.setString(&c.Auth.TOTPIssuer, "TOTP_ISSUER")
+
+	env.setString(&c.Mail.Driver, "MAIL_DRIVER")
+	env.setString(&c.Mail.From, "MAIL_FROM")
+	env.setString(&c.Mail.OutboxDir, "MAIL_OUTBOX_DIR")
+	env.setString(&c.Mail.SMTPHost, "SMTP_HOST")
+	env.setString(&c.Mail.SMTPPort, "SMTP_PORT")
+	env.setString(&c.Mail.SMTPUser, "SMTP_USER")
+	env.setString(&c.Mail.SMTPPass, "SMTP_PASS")
+
+	env.setString(&c.SMS.Driver, "SMS_DRIVER")
+	env.setString(&c.SMS.OutboxDir, "SMS_OUTBOX_DIR")
+	env.setString(&c.SMS.WebhookURL, "SMS_WEBHOOK_URL")
+
+	env.setString(&c.Region.BaseURL, "REGION_API_BASE_URL")
+
+	errs = append(errs, env.setDuration(&c.OIDC.StateTTL, "OIDC_STATE_TTL"))
+	c.applyOIDCEnv(env)
+
+	return errors.Join(errs...)
+}
+
+// applyOIDCEnv: OIDC_PROVIDERS=google,mock menggantikan daftar provider;
+// setiap provider dibaca dari OIDC_<NAMA>_ISSUER, _CLIENT_ID, _CLIENT_SECRET,
+// _REDIRECT_URL, _SCOPES dan _DISPLAY_NAME. Provider dengan nama yang sama
+// di file YAML dipakai sebagai nilai awal.
+func (c *Config) applyOIDCEnv(env envSource) {
+	v, ok := env.get("OIDC_PROVIDERS")
+	if !ok {
+		return
+	}
+	fromFile := map[string]OIDCProviderConfig{}
+	for _, p := range c.OIDC.Providers {
+		fromFile[p.Name] = p
+	}
+	var providers []OIDCProviderConfig
+	for _, name := range splitList(v) {
+		p := fromFile[name]
+		p.Name = name
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		env.setString(&p.DisplayName, prefix+"DISPLAY_NAME")
+		env.setString(&p.Issuer, prefix+"ISSUER")
+		env.setString(&p.ClientID, prefix+"CLIENT_ID")
+		env.setString(&p.ClientSecret, prefix+"CLIENT_SECRET")
+		env.setString(&p.RedirectURL, prefix+"REDIRECT_URL")
+		if scopes, ok := env.get(prefix + "SCOPES"); ok {
+			p.Scopes = splitList(scopes)
+		}
+		providers = append(providers, p)
+	}
+	c.OIDC.Providers = providers
+}
+
+// Validate reports every invalid setting at once so startup fails with a
+// complete list instead of one error per restart.
+func (c *Config) Validate() error {
+	var problems []string
+
+	if _, err := strconv.Atoi(c.App.Port); err != nil {
+		problems = append(problems, fmt.Sprintf("APP_PORT %q harus berupa angka", c.App.Port))
+	}
+	if c.App.UploadDir == "" {
+		problems = append(problems, "UPLOAD_DIR tidak boleh kosong")
+	}
+	if c.App.ShutdownTimeout <= 0 {
+		problems = append(problems, "SHUTDOWN_TIMEOUT harus lebih dari 0")
+	}
 
-	username := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASS")
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
+	switch c.DB.Driver {
+	case DriverMySQL, DriverPostgres:
+		if c.DB.DSN == "" && (c.DB.Host == "" || c.DB.Name == "") {
+			problems = append(problems, fmt.Sprintf("DB_HOST dan DB_NAME (atau DB_DSN) wajib untuk driver %s", c.DB.Driver))
+		}
+	case DriverSQLite:
+	default:
+		problems = append(problems, fmt.Sprintf("DB_DRIVER %q tidak dikenal (mysql, postgres, sqlite)", c.DB.Driver))
+	}
+	if c.DB.MaxOpenConns < 0 || c.DB.MaxIdleConns < 0 {
+		problems = append(problems, "DB_MAX_OPEN_CONNS dan DB_MAX_IDLE_CONNS tidak boleh negatif")
+	}
+	if c.DB.MaxOpenConns > 0 && c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		problems = append(problems, "DB_MAX_IDLE_CONNS tidak boleh melebihi DB_MAX_OPEN_CONNS")
+	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		username, password, host, port, dbName)
+	switch c.JWT.Algorithm {
+	case JWTAlgHS256:
+		if c.JWT.Secret == "" {
+			problems = append(problems, "JWT_SECRET wajib diisi untuk JWT_ALGORITHM=HS256")
+		}
+	case JWTAlgRS256, JWTAlgEdDSA:
+		if c.JWT.PrivateKeyFile == "" {
+			problems = append(problems, fmt.Sprintf("JWT_PRIVATE_KEY_FILE wajib diisi untuk JWT_ALGORITHM=%s", c.JWT.Algorithm))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("JWT_ALGORITHM %q tidak dikenal (HS256, RS256, EdDSA)", c.JWT.Algorithm))
+	}
+	if c.JWT.Issuer == "" || c.JWT.Audience == "" {
+		problems = append(problems, "JWT_ISSUER dan JWT_AUDIENCE wajib diisi")
+	}
+	if c.JWT.AccessTTL <= 0 {
+		problems = append(problems, "JWT_ACCESS_TTL harus lebih dari 0")
+	}
+	if c.JWT.RefreshTTL <= c.JWT.AccessTTL {
+		problems = append(problems, "JWT_REFRESH_TTL harus lebih lama dari JWT_ACCESS_TTL")
+	}
 
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if c.Auth.PasswordResetTTL <= 0 {
+		problems = append(problems, "PASSWORD_RESET_TTL harus lebih dari 0")
+	}
+	if c.Auth.OTPTTL <= 0 {
+		problems = append(problems, "OTP_TTL harus lebih dari 0")
+	}
+	if c.Auth.OTPResendInterval < 0 {
+		problems = append(problems, "OTP_RESEND_INTERVAL tidak boleh negatif")
+	}
+	if c.Auth.OTPMaxPerHour <= 0 || c.Auth.OTPMaxAttempts <= 0 {
+		problems = append(problems, "OTP_MAX_PER_HOUR dan OTP_MAX_ATTEMPTS harus lebih dari 0")
+	}
+	if c.Auth.LoginMaxAttempts <= 0 || c.Auth.LoginIPMaxFailures <= 0 {
+		problems = append(problems, "LOGIN_MAX_ATTEMPTS dan LOGIN_IP_MAX_FAILURES harus lebih dari 0")
+	}
+	if c.Auth.LoginLockout <= 0 || c.Auth.LoginIPWindow <= 0 {
+		problems = append(problems, "LOGIN_LOCKOUT dan LOGIN_IP_WINDOW harus lebih dari 0")
+	}
+	if c.Auth.LoginBackoffBase < 0 || c.Auth.LoginBackoffMax < c.Auth.LoginBackoffBase {
+		problems = append(problems, "LOGIN_BACKOFF_MAX tidak boleh lebih kecil dari LOGIN_BACKOFF_BASE")
+	}
+	if c.Auth.TOTPIssuer == "" || strings.Contains(c.Auth.TOTPIssuer, ":") {
+		problems = append(problems, "TOTP_ISSUER wajib diisi dan tidak boleh mengandung ':'")
+	}
+	if c.Auth.TwoFactorChallengeTTL <= 0 || c.Auth.TwoFactorMaxAttempts <= 0 {
+		problems = append(problems, "TWO_FACTOR_CHALLENGE_TTL dan TWO_FACTOR_MAX_ATTEMPTS harus lebih dari 0")
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "outbox":
+		if c.Mail.OutboxDir == "" {
+			problems = append(problems, "MAIL_OUTBOX_DIR wajib untuk MAIL_DRIVER=outbox")
+		}
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			problems = append(problems, "SMTP_HOST wajib untuk MAIL_DRIVER=smtp")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("MAIL_DRIVER %q tidak dikenal (log, outbox, smtp)", c.Mail.Driver))
+	}
+	if c.Mail.From == "" {
+		problems = append(problems, "MAIL_FROM wajib diisi")
+	}
+
+	switch c.SMS.Driver {
+	case "log":
+	case "outbox":
+		if c.SMS.OutboxDir == "" {
+			problems = append(problems, "SMS_OUTBOX_DIR wajib untuk SMS_DRIVER=outbox")
+		}
+	case "webhook":
+		if !strings.HasPrefix(c.SMS.WebhookURL, "http://") && !strings.HasPrefix(c.SMS.WebhookURL, "https://") {
+			problems = append(problems, "SMS_WEBHOOK_URL harus berupa URL http(s) untuk SMS_DRIVER=webhook")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("SMS_DRIVER %q tidak dikenal (log, outbox, webhook)", c.SMS.Driver))
+	}
+
+	if !strings.HasPrefix(c.Region.BaseURL, "http://") && !strings.HasPrefix(c.Region.BaseURL, "https://") {
+		problems = append(problems, fmt.Sprintf("REGION_API_BASE_URL %q harus berupa URL http(s)", c.Region.BaseURL))
+	}
+
+	if c.OIDC.StateTTL <= 0 {
+		problems = append(problems, "OIDC_STATE_TTL harus lebih dari 0")
+	}
+	seen := map[string]bool{}
+	for _, p := range c.OIDC.Providers {
+		if !validProviderName(p.Name) {
+			problems = append(problems, fmt.Sprintf("nama provider OIDC %q hanya boleh huruf kecil, angka dan '-'", p.Name))
+			continue
+		}
+		if seen[p.Name] {
+			problems = append(problems, fmt.Sprintf("provider OIDC %q didefinisikan dua kali", p.Name))
+		}
+		seen[p.Name] = true
+		if !isHTTPURL(p.Issuer) || !isHTTPURL(p.RedirectURL) {
+			problems = append(problems, fmt.Sprintf("provider OIDC %s: issuer dan redirect_url harus berupa URL http(s)", p.Name))
+		}
+		if p.ClientID == "" {
+			problems = append(problems, fmt.Sprintf("provider OIDC %s: client_id wajib diisi", p.Name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("konfigurasi tidak valid:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// ListenAddr returns the address passed to fiber.App.Listen
+func (c AppConfig) ListenAddr() string {
+	return ":" + c.Port
+}
+
+// envSource looks up one configuration layer: the process environment or
+// the parsed .env file
+type envSource func(key string) (string, bool)
+
+// get treats an empty value as unset
+func (env envSource) get(key string) (string, bool) {
+	v, ok := env(key)
+	if !ok || v == "" {
+		return "", false
+	}
+	return v, true
+}
+
+func (env envSource) setString(dst *string, key string) {
+	if v, ok := env.get(key); ok {
+		*dst = v
+	}
+}
+
+func (env envSource) setInt(dst *int, key string) error {
+	v, ok := env.get(key)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s %q harus berupa angka", key, v)
+	}
+	*dst = n
+	return nil
+}
+
+func (env envSource) setBool(dst *bool, key string) error {
+	v, ok := env.get(key)
+	if !ok {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s %q harus true atau false", key, v)
+	}
+	*dst = b
+	return nil
+}
+
+func (env envSource) setDuration(dst *time.Duration, key string) error {
+	v, ok := env.get(key)
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
 	if err != nil {
-		log.Fatal("❌ Gagal koneksi database:", err)
+		return fmt.Errorf("%s %q bukan durasi yang valid (contoh: 15m, 72h)", key, v)
 	}
+	*dst = d
+	return nil
+}
 
-	fmt.Println("✅ Database connected")
+func isHTTPURL(v string) bool {
+	return strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://")
+}
+
+func validProviderName(name string) bool {
+	if name == "" || len(name) > 32 {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
 }
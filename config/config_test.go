@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadPrecedence: defaults < .env < CONFIG_FILE < process environment,
+// and an empty variable never clears a value from a lower layer.
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFile(t, filepath.Join(dir, ".env"), `
+DB_DRIVER=mysql
+DB_HOST=127.0.0.1
+DB_NAME=from_dotenv
+JWT_SECRET=from_dotenv
+APP_PORT=8001
+UPLOAD_DIR=from_dotenv
+`)
+	yamlPath := filepath.Join(dir, "config.yaml")
+	writeFile(t, yamlPath, `
+app:
+  port: "8002"
+  upload_dir: from_yaml
+  cors_origins:
+    - http://localhost:3000
+jwt:
+  secret: from_yaml
+`)
+	t.Setenv("CONFIG_FILE", yamlPath)
+	t.Setenv("APP_PORT", "")
+	t.Setenv("UPLOAD_DIR", "from_env")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("CORS_ORIGINS", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	checks := []struct {
+		name, got, want string
+	}{
+		{"DB_NAME (.env only)", cfg.DB.Name, "from_dotenv"},
+		{"APP_PORT (YAML over .env, empty env skipped)", cfg.App.Port, "8002"},
+		{"JWT_SECRET (YAML over .env, empty env skipped)", cfg.JWT.Secret, "from_yaml"},
+		{"UPLOAD_DIR (env over YAML)", cfg.App.UploadDir, "from_env"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+	if len(cfg.App.CORSOrigins) != 1 {
+		t.Errorf("CORS_ORIGINS = %v, want the YAML list (empty env skipped)", cfg.App.CORSOrigins)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Driver database yang didukung lewat DB_DRIVER
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// OpenDB membuka koneksi GORM sesuai driver dan mengatur connection pool
+func OpenDB(cfg DBConfig) (*gorm.DB, error) {
+	dialector, err := openDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		// Error driver (unique violation) diterjemahkan ke gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal koneksi database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	// SQLite in-memory hanya hidup selama koneksinya terbuka,
+	// jadi pool dibatasi satu koneksi agar semua query melihat data yang sama
+	if cfg.Driver == DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	}
+
+	return db, nil
+}
+
+// openDialector memilih dialector GORM sesuai driver.
+// DSN dipakai apa adanya jika diisi, selain itu DSN dibangun dari field lain.
+func openDialector(cfg DBConfig) (gorm.Dialector, error) {
+	dsn := cfg.DSN
+
+	switch cfg.Driver {
+	case DriverMySQL:
+		if dsn == "" {
+			dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+				cfg.User, cfg.Pass, cfg.Host, cfg.Port, cfg.Name)
+		}
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		if dsn == "" {
+			dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
+				cfg.Host, cfg.Port, cfg.User, cfg.Pass, cfg.Name, cfg.SSLMode)
+		}
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		// Name berisi path file, atau ":memory:" untuk database sementara
+		if dsn == "" {
+			dsn = cfg.Name
+		}
+		if dsn == "" {
+			dsn = ":memory:"
+		}
+		return sqlite.Open(withSQLiteForeignKeys(dsn)), nil
+	default:
+		return nil, fmt.Errorf("DB_DRIVER %q tidak dikenal (mysql, postgres, sqlite)", cfg.Driver)
+	}
+}
+
+// withSQLiteForeignKeys mengaktifkan foreign key SQLite yang default-nya mati
+func withSQLiteForeignKeys(dsn string) string {
+	if strings.Contains(dsn, "_foreign_keys") || strings.Contains(dsn, "_fk") {
+		return dsn
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "_foreign_keys=on"
+}
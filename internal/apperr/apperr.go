@@ -0,0 +1,177 @@
+// Package apperr defines the typed errors shared by services and handlers.
+// Every error carries a Code that decides the HTTP status; the central Fiber
+// ErrorHandler turns it into the {status, code, message, details} envelope.
+package apperr
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Code string
+
+const (
+	CodeBadRequest      Code = "BAD_REQUEST"
+	CodeUnauthorized    Code = "UNAUTHORIZED"
+	CodeForbidden       Code = "FORBIDDEN"
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeConflict        Code = "CONFLICT"
+	CodeValidation      Code = "VALIDATION_FAILED"
+	CodeTooManyRequests Code = "TOO_MANY_REQUESTS"
+	CodeUpstream        Code = "UPSTREAM_ERROR"
+	CodeInternal        Code = "INTERNAL_ERROR"
+)
+
+var statusByCode = map[Code]int{
+	CodeBadRequest:      http.StatusBadRequest,
+	CodeUnauthorized:    http.StatusUnauthorized,
+	CodeForbidden:       http.StatusForbidden,
+	CodeNotFound:        http.StatusNotFound,
+	CodeConflict:        http.StatusConflict,
+	CodeValidation:      http.StatusUnprocessableEntity,
+	CodeTooManyRequests: http.StatusTooManyRequests,
+	CodeUpstream:        http.StatusBadGateway,
+	CodeInternal:        http.StatusInternalServerError,
+}
+
+// Status is the HTTP status code for c
+func (c Code) Status() int {
+	if status, ok := statusByCode[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeForStatus is the inverse of Code.Status. Statuses without a Code of
+// their own become e.g. METHOD_NOT_ALLOWED.
+func CodeForStatus(status int) Code {
+	for code, s := range statusByCode {
+		if s == status {
+			return code
+		}
+	}
+	if text := http.StatusText(status); text != "" {
+		return Code(strings.ToUpper(strings.ReplaceAll(text, " ", "_")))
+	}
+	return CodeInternal
+}
+
+// Error is a domain error. Message is shown to the client; Err is the
+// underlying cause and only ends up in server logs.
+type Error struct {
+	Code    Code
+	Message string
+	Details interface{}
+	Err     error
+
+	// RetryAfter > 0 dikirim ke client sebagai header Retry-After
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is makes a wrapped copy (see Wrap, WithDetails) match its sentinel
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && t.Message == e.Message
+}
+
+// Wrap returns a copy of e with cause attached
+func (e *Error) Wrap(cause error) *Error {
+	cp := *e
+	cp.Err = cause
+	return &cp
+}
+
+// WithDetails returns a copy of e carrying details for the client
+func (e *Error) WithDetails(details interface{}) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// FieldError is one entry of the details of a VALIDATION_FAILED error
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// InvalidField is a validation error about a single request field, used by
+// services for business rules (e.g. an unknown id_category)
+func InvalidField(field, message string) *Error {
+	return &Error{
+		Code:    CodeValidation,
+		Message: message,
+		Details: []FieldError{{Field: field, Message: message}},
+	}
+}
+
+// Throttled is a TooManyRequests error telling the client when to retry
+func Throttled(message string, retryAfter time.Duration) *Error {
+	return &Error{Code: CodeTooManyRequests, Message: message, RetryAfter: retryAfter}
+}
+
+func BadRequest(message string) *Error      { return New(CodeBadRequest, message) }
+func Unauthorized(message string) *Error    { return New(CodeUnauthorized, message) }
+func Forbidden(message string) *Error       { return New(CodeForbidden, message) }
+func NotFound(message string) *Error        { return New(CodeNotFound, message) }
+func Conflict(message string) *Error        { return New(CodeConflict, message) }
+func Validation(message string) *Error      { return New(CodeValidation, message) }
+func TooManyRequests(message string) *Error { return New(CodeTooManyRequests, message) }
+func Upstream(message string) *Error        { return New(CodeUpstream, message) }
+
+// As returns the *Error in err's chain, if any
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// CodeOf returns the code of err, CodeInternal for untyped errors
+func CodeOf(err error) Code {
+	if e, ok := As(err); ok {
+		return e.Code
+	}
+	return CodeInternal
+}
+
+// IsNotFound reports whether err is a missing row or a NotFound error
+func IsNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || CodeOf(err) == CodeNotFound
+}
+
+// IsDuplicateKey reports whether err is a unique constraint violation.
+// Needs gorm.Config.TranslateError so the driver error becomes
+// gorm.ErrDuplicatedKey.
+func IsDuplicateKey(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// FromDB translates a repository error: a missing row becomes notFound and a
+// unique violation becomes conflict (either may be nil to keep the original
+// error). Other errors are returned unchanged.
+func FromDB(err error, notFound, conflict *Error) error {
+	switch {
+	case err == nil:
+		return nil
+	case notFound != nil && errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound.Wrap(err)
+	case conflict != nil && IsDuplicateKey(err):
+		return conflict.Wrap(err)
+	}
+	return err
+}
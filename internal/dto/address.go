@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"FinalTask/internal/models"
+)
+
+type AddressResponse struct {
+	ID           uint      `json:"ID"`
+	JudulAlamat  string    `json:"JudulAlamat"`
+	NamaPenerima string    `json:"NamaPenerima"`
+	NoTelp       string    `json:"NoTelp"`
+	DetailAlamat string    `json:"DetailAlamat"`
+	CreatedAt    time.Time `json:"CreatedAt"`
+	UpdatedAt    time.Time `json:"UpdatedAt"`
+}
+
+func NewAddressResponse(a *models.Alamat) AddressResponse {
+	return AddressResponse{
+		ID:           a.ID,
+		JudulAlamat:  a.JudulAlamat,
+		NamaPenerima: a.NamaPenerima,
+		NoTelp:       a.NoTelp,
+		DetailAlamat: a.DetailAlamat,
+		CreatedAt:    a.CreatedAt,
+		UpdatedAt:    a.UpdatedAt,
+	}
+}
+
+func NewAddressResponses(list []*models.Alamat) []AddressResponse {
+	return mapList(list, NewAddressResponse)
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	"FinalTask/internal/models"
+)
+
+type CategoryResponse struct {
+	ID           uint      `json:"ID"`
+	NamaCategory string    `json:"NamaCategory"`
+	CreatedAt    time.Time `json:"CreatedAt"`
+	UpdatedAt    time.Time `json:"UpdatedAt"`
+}
+
+func NewCategoryResponse(c *models.Category) CategoryResponse {
+	return CategoryResponse{
+		ID:           c.ID,
+		NamaCategory: c.NamaCategory,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+}
+
+func NewCategoryResponses(list []*models.Category) []CategoryResponse {
+	return mapList(list, NewCategoryResponse)
+}
@@ -0,0 +1,14 @@
+// Package dto holds the JSON shapes returned by the API. Handlers map models
+// to these types instead of serializing GORM models, so renaming a model
+// field never changes a response and fields such as User.Password or a
+// seller's email can never leak through a preload.
+package dto
+
+// mapList maps every element; the result is never nil so lists encode as []
+func mapList[M any, R any](list []M, fn func(M) R) []R {
+	out := make([]R, 0, len(list))
+	for _, item := range list {
+		out = append(out, fn(item))
+	}
+	return out
+}
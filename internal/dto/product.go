@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"time"
+
+	"FinalTask/internal/models"
+)
+
+// ProductResponse is a product with its category, photos and the public
+// view of its seller
+type ProductResponse struct {
+	ID            uint             `json:"ID"`
+	IDLogProduk   *uint            `json:"IDLogProduk"` // versi terkini
+	NamaProduk    string           `json:"NamaProduk"`
+	Slug          string           `json:"Slug"`
+	HargaReseller models.Money     `json:"HargaReseller"`
+	HargaKonsumen models.Money     `json:"HargaKonsumen"`
+	Stok          int              `json:"Stok"`
+	Deskripsi     string           `json:"Deskripsi"`
+	Status        string           `json:"Status"`
+	Toko          SellerResponse   `json:"Toko"`
+	Category      CategoryResponse `json:"Category"`
+	Photos        []PhotoResponse  `json:"FotoProduk"`
+	CreatedAt     time.Time        `json:"CreatedAt"`
+	UpdatedAt     time.Time        `json:"UpdatedAt"`
+}
+
+type PhotoResponse struct {
+	ID  uint   `json:"ID"`
+	URL string `json:"URL"`
+}
+
+func NewProductResponse(p *models.Produk) ProductResponse {
+	return ProductResponse{
+		ID:            p.ID,
+		IDLogProduk:   p.IDLogProduk,
+		NamaProduk:    p.NamaProduk,
+		Slug:          p.Slug,
+		HargaReseller: p.HargaReseller,
+		HargaKonsumen: p.HargaKonsumen,
+		Stok:          p.Stok,
+		Deskripsi:     p.Deskripsi,
+		Status:        p.Status,
+		Toko:          NewSellerResponse(&p.Toko),
+		Category:      NewCategoryResponse(&p.Category),
+		Photos:        newPhotoResponses(p.FotoProduk),
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+func NewProductResponses(list []*models.Produk) []ProductResponse {
+	return mapList(list, NewProductResponse)
+}
+
+func newPhotoResponses(list []models.FotoProduk) []PhotoResponse {
+	return mapList(list, func(f models.FotoProduk) PhotoResponse {
+		return PhotoResponse{ID: f.ID, URL: f.URL}
+	})
+}
+
+// ProductVersionResponse is one LogProduk snapshot; Current marks the
+// version checkout binds to
+type ProductVersionResponse struct {
+	ID            uint         `json:"ID"`
+	Versi         int          `json:"Versi"`
+	Current       bool         `json:"Current"`
+	NamaProduk    string       `json:"NamaProduk"`
+	Slug          string       `json:"Slug"`
+	HargaReseller models.Money `json:"HargaReseller"`
+	HargaKonsumen models.Money `json:"HargaKonsumen"`
+	Deskripsi     string       `json:"Deskripsi"`
+	IDCategory    uint         `json:"IDCategory"`
+	StokAwal      int          `json:"StokAwal"`
+	CreatedAt     time.Time    `json:"CreatedAt"`
+}
+
+func NewProductVersionResponses(p *models.Produk, logs []*models.LogProduk) []ProductVersionResponse {
+	return mapList(logs, func(l *models.LogProduk) ProductVersionResponse {
+		return ProductVersionResponse{
+			ID:            l.ID,
+			Versi:         l.Versi,
+			Current:       p.IDLogProduk != nil && *p.IDLogProduk == l.ID,
+			NamaProduk:    l.NamaProduk,
+			Slug:          l.Slug,
+			HargaReseller: l.HargaReseller,
+			HargaKonsumen: l.HargaKonsumen,
+			Deskripsi:     l.Deskripsi,
+			IDCategory:    l.IDCategory,
+			StokAwal:      l.StokAwal,
+			CreatedAt:     l.CreatedAt,
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"FinalTask/internal/models"
+)
+
+// SellerResponse is the public view of a Toko inside a product: no owner
+// data at all
+type SellerResponse struct {
+	ID       uint   `json:"ID"`
+	NamaToko string `json:"NamaToko"`
+	URLFoto  string `json:"URLFoto"`
+}
+
+// StoreResponse is a Toko as seen by its owner (GET /store) or by staff
+// (GET /stores); only the owner's ID is included
+type StoreResponse struct {
+	ID        uint                   `json:"ID"`
+	IDUser    uint                   `json:"IDUser"`
+	NamaToko  string                 `json:"NamaToko"`
+	URLFoto   string                 `json:"URLFoto"`
+	Products  []StoreProductResponse `json:"Produk"`
+	CreatedAt time.Time              `json:"CreatedAt"`
+	UpdatedAt time.Time              `json:"UpdatedAt"`
+}
+
+// StoreProductResponse is a product listed inside its store
+type StoreProductResponse struct {
+	ID            uint            `json:"ID"`
+	NamaProduk    string          `json:"NamaProduk"`
+	Slug          string          `json:"Slug"`
+	HargaKonsumen models.Money    `json:"HargaKonsumen"`
+	Stok          int             `json:"Stok"`
+	Status        string          `json:"Status"`
+	Photos        []PhotoResponse `json:"FotoProduk"`
+}
+
+func NewSellerResponse(t *models.Toko) SellerResponse {
+	return SellerResponse{ID: t.ID, NamaToko: t.NamaToko, URLFoto: t.URLFoto}
+}
+
+func NewStoreResponse(t *models.Toko) StoreResponse {
+	return StoreResponse{
+		ID:       t.ID,
+		IDUser:   t.IDUser,
+		NamaToko: t.NamaToko,
+		URLFoto:  t.URLFoto,
+		Products: mapList(t.Produk, func(p models.Produk) StoreProductResponse {
+			return StoreProductResponse{
+				ID:            p.ID,
+				NamaProduk:    p.NamaProduk,
+				Slug:          p.Slug,
+				HargaKonsumen: p.HargaKonsumen,
+				Stok:          p.Stok,
+				Status:        p.Status,
+				Photos:        newPhotoResponses(p.FotoProduk),
+			}
+		}),
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func NewStoreResponses(list []*models.Toko) []StoreResponse {
+	return mapList(list, NewStoreResponse)
+}
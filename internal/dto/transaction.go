@@ -0,0 +1,119 @@
+package dto
+
+import (
+	"time"
+
+	"FinalTask/internal/models"
+)
+
+// TransactionResponse keeps the field names the Trx model used to expose
+type TransactionResponse struct {
+	ID               uint                      `json:"id"`
+	IDUser           uint                      `json:"id_user"`
+	AlamatPengiriman uint                      `json:"alamat_pengiriman"`
+	MethodBayar      string                    `json:"method_bayar"`
+	Status           string                    `json:"status"`
+	HargaTotal       models.Money              `json:"harga_total"`
+	KodeInvoice      string                    `json:"kode_invoice"`
+	DetailTrx        []TransactionItemResponse `json:"detail_trx"`
+	// Timeline hanya diisi di detail transaksi, urut dari event pertama
+	Timeline []OrderEventResponse `json:"timeline,omitempty"`
+	Refund   *RefundResponse      `json:"refund,omitempty"`
+	// CheckoutOrders hanya diisi saat checkout dipecah per toko: ID semua
+	// transaksi dari checkout yang sama, termasuk yang ini
+	CheckoutOrders []uint    `json:"checkout_orders,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type TransactionItemResponse struct {
+	ID          uint         `json:"ID"`
+	IDLogProduk uint         `json:"IDLogProduk"`
+	IDToko      uint         `json:"IDToko"`
+	Kuantitas   int          `json:"Kuantitas"`
+	HargaTotal  models.Money `json:"HargaTotal"`
+}
+
+// OrderEventResponse is one status change in the order timeline
+type OrderEventResponse struct {
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	ActorID   *uint     `json:"actor_id"`
+	ActorRole string    `json:"actor_role"`
+	Note      string    `json:"note,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+func newOrderEventResponse(e models.OrderEvent) OrderEventResponse {
+	return OrderEventResponse{
+		From:      e.FromStatus,
+		To:        e.ToStatus,
+		ActorID:   e.IDActor,
+		ActorRole: e.ActorRole,
+		Note:      e.Note,
+		At:        e.CreatedAt,
+	}
+}
+
+// RefundResponse is the refund of a paid order that was cancelled or returned
+type RefundResponse struct {
+	Amount      models.Money `json:"amount"`
+	Status      string       `json:"status"`
+	Reason      string       `json:"reason"`
+	CompletedAt *time.Time   `json:"completed_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+func newRefundResponse(r *models.Refund) *RefundResponse {
+	if r == nil {
+		return nil
+	}
+	return &RefundResponse{
+		Amount:      r.Amount,
+		Status:      r.Status,
+		Reason:      r.Reason,
+		CompletedAt: r.CompletedAt,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func NewTransactionResponse(t *models.Trx) TransactionResponse {
+	return TransactionResponse{
+		ID:               t.ID,
+		IDUser:           t.IDUser,
+		AlamatPengiriman: t.AlamatPengiriman,
+		MethodBayar:      t.MethodBayar,
+		Status:           t.Status,
+		HargaTotal:       t.HargaTotal,
+		KodeInvoice:      t.KodeInvoice,
+		DetailTrx: mapList(t.DetailTrx, func(d models.DetailTrx) TransactionItemResponse {
+			return TransactionItemResponse{
+				ID:          d.ID,
+				IDLogProduk: d.IDLogProduk,
+				IDToko:      d.IDToko,
+				Kuantitas:   d.Kuantitas,
+				HargaTotal:  d.HargaTotal,
+			}
+		}),
+		Timeline:  mapList(t.Events, newOrderEventResponse),
+		Refund:    newRefundResponse(t.Refund),
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+// NewCheckoutResponse is the first order of a checkout; checkout_orders lists
+// every order when the cart was split per store
+func NewCheckoutResponse(orders []*models.Trx) TransactionResponse {
+	resp := NewTransactionResponse(orders[0])
+	if len(orders) > 1 {
+		for _, t := range orders {
+			resp.CheckoutOrders = append(resp.CheckoutOrders, t.ID)
+		}
+	}
+	return resp
+}
+
+func NewTransactionResponses(list []*models.Trx) []TransactionResponse {
+	return mapList(list, NewTransactionResponse)
+}
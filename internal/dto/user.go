@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"FinalTask/internal/models"
+)
+
+// UserResponse is the profile of the logged-in user (GET /me, register)
+type UserResponse struct {
+	ID               uint      `json:"ID"`
+	Nama             string    `json:"Nama"`
+	Email            string    `json:"Email"`
+	NoTelp           string    `json:"NoTelp"`
+	TanggalLahir     *string   `json:"TanggalLahir"` // YYYY-MM-DD
+	JenisKelamin     string    `json:"JenisKelamin"`
+	Tentang          string    `json:"Tentang"`
+	Pekerjaan        string    `json:"Pekerjaan"`
+	IDProvinsi       string    `json:"IDProvinsi"`
+	IDKota           string    `json:"IDKota"`
+	IsAdmin          bool      `json:"IsAdmin"`
+	EmailVerified    bool      `json:"EmailVerified"`
+	PhoneVerified    bool      `json:"PhoneVerified"`
+	TwoFactorEnabled bool      `json:"TwoFactorEnabled"`
+	CreatedAt        time.Time `json:"CreatedAt"`
+	UpdatedAt        time.Time `json:"UpdatedAt"`
+}
+
+// ProfileResponse is returned by PUT /me together with the region names
+type ProfileResponse struct {
+	User         UserResponse `json:"user"`
+	ProvinceName string       `json:"province_name"`
+	CityName     string       `json:"city_name"`
+}
+
+func NewUserResponse(u *models.User) UserResponse {
+	res := UserResponse{
+		ID:               u.ID,
+		Nama:             u.Nama,
+		Email:            u.Email,
+		JenisKelamin:     u.JenisKelamin,
+		Tentang:          u.Tentang,
+		Pekerjaan:        u.Pekerjaan,
+		IDProvinsi:       u.IDProvinsi,
+		IDKota:           u.IDKota,
+		IsAdmin:          u.IsAdmin,
+		EmailVerified:    u.EmailVerifiedAt != nil,
+		PhoneVerified:    u.PhoneVerifiedAt != nil,
+		TwoFactorEnabled: u.HasTwoFactor(),
+		CreatedAt:        u.CreatedAt,
+		UpdatedAt:        u.UpdatedAt,
+	}
+	// Placeholder nomor dari login OIDC tidak ditampilkan
+	if u.HasPhone() {
+		res.NoTelp = u.NoTelp
+	}
+	if u.TanggalLahir != nil {
+		dob := u.TanggalLahir.Format("2006-01-02")
+		res.TanggalLahir = &dob
+	}
+	return res
+}
@@ -3,7 +3,8 @@ package handler
 import (
 	"strconv"
 
-	"FinalTask/internal/middleware"
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/dto"
 	"FinalTask/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,11 +14,11 @@ type AddressHandler struct {
 	AddressService service.AddressService
 }
 
-func NewAddressHandler(r fiber.Router, addressService service.AddressService) {
+func NewAddressHandler(r fiber.Router, auth fiber.Handler, addressService service.AddressService) {
 	h := &AddressHandler{AddressService: addressService}
 
 	// Protected: CRUD alamat
-	addrGroup := r.Group("/addresses", middleware.JWTProtected())
+	addrGroup := r.Group("/addresses", auth)
 	addrGroup.Post("", h.CreateAddress)
 	addrGroup.Get("", h.ListAddress)
 	addrGroup.Get("/:id", h.GetAddressByID)
@@ -37,25 +38,19 @@ func (h *AddressHandler) CreateAddress(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 
 	var req service.CreateAddressRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request payload",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	addr, err := h.AddressService.Create(c.Context(), userID, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"address": addr,
+			"address": dto.NewAddressResponse(addr),
 		},
 	})
 }
@@ -65,16 +60,13 @@ func (h *AddressHandler) ListAddress(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 	list, err := h.AddressService.List(c.Context(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Failed to list addresses",
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"addresses": list,
+			"addresses": dto.NewAddressResponses(list),
 		},
 	})
 }
@@ -85,23 +77,17 @@ func (h *AddressHandler) GetAddressByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid address ID",
-		})
+		return apperr.BadRequest("Invalid address ID")
 	}
 	id := uint(id64)
 
 	addr, err := h.AddressService.GetByID(c.Context(), userID, id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
-		"data":   fiber.Map{"address": addr},
+		"data":   fiber.Map{"address": dto.NewAddressResponse(addr)},
 	})
 }
 
@@ -111,33 +97,24 @@ func (h *AddressHandler) UpdateAddress(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid address ID",
-		})
+		return apperr.BadRequest("Invalid address ID")
 	}
 	id := uint(id64)
 
 	var req service.CreateAddressRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request payload",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	updated, err := h.AddressService.Update(c.Context(), userID, id, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"address": updated,
+			"address": dto.NewAddressResponse(updated),
 		},
 	})
 }
@@ -148,18 +125,12 @@ func (h *AddressHandler) DeleteAddress(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid address ID",
-		})
+		return apperr.BadRequest("Invalid address ID")
 	}
 	id := uint(id64)
 
 	if err := h.AddressService.Delete(c.Context(), userID, id); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusNoContent).JSON(fiber.Map{
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"strconv"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyHandler struct {
+	APIKeyService service.APIKeyService
+}
+
+// NewAPIKeyHandler registers /store/api-keys. Keys are managed with a user
+// JWT only, so a leaked key cannot mint new keys.
+func NewAPIKeyHandler(r fiber.Router, auth fiber.Handler, apiKeyService service.APIKeyService) {
+	h := &APIKeyHandler{APIKeyService: apiKeyService}
+	group := r.Group("/store/api-keys", auth)
+
+	group.Get("", h.ListKeys)
+	group.Post("", h.CreateKey)
+	group.Delete("/:id", h.RevokeKey)
+}
+
+// ListKeys handles GET /store/api-keys
+func (h *APIKeyHandler) ListKeys(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	list, err := h.APIKeyService.List(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"api_keys": list,
+		},
+	})
+}
+
+// CreateKey handles POST /store/api-keys. The key is only shown in this response.
+func (h *APIKeyHandler) CreateKey(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	var req service.CreateAPIKeyRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	key, err := h.APIKeyService.Create(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Store this key now, it will not be shown again",
+		"data": fiber.Map{
+			"api_key": key,
+		},
+	})
+}
+
+// RevokeKey handles DELETE /store/api-keys/:id
+func (h *APIKeyHandler) RevokeKey(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid API key ID")
+	}
+	if err := h.APIKeyService.Revoke(c.Context(), userID, uint(id64)); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "API key revoked",
+	})
+}
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"strconv"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/dto"
+	"FinalTask/internal/middleware"
+	"FinalTask/internal/models"
 	"FinalTask/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,60 +16,226 @@ type AuthHandler struct {
 	AuthService service.AuthService
 }
 
-func NewAuthHandler(r fiber.Router, authService service.AuthService) {
+func NewAuthHandler(r fiber.Router, auth fiber.Handler, authService service.AuthService) {
 	h := &AuthHandler{AuthService: authService}
 
 	authGroup := r.Group("/auth")
 	authGroup.Post("/register", h.Register)
 	authGroup.Post("/login", h.Login)
+	authGroup.Post("/refresh", h.Refresh)
+	authGroup.Post("/logout", h.Logout)
+	authGroup.Post("/forgot-password", h.ForgotPassword)
+	authGroup.Post("/reset-password", h.ResetPassword)
+	authGroup.Post("/change-password", auth, h.ChangePassword)
+
+	// Langkah kedua login (2FA), memakai challenge_token dari /auth/login
+	authGroup.Post("/2fa/verify", h.VerifyTwoFactor)
+	authGroup.Post("/2fa/setup", h.SetupTwoFactor)
+
+	// Admin: buka kunci akun & audit login
+	r.Post("/admin/users/:id/unlock", auth, middleware.RequirePermission(models.PermUserUnlock), h.UnlockUser)
+	r.Get("/admin/users/:id/logins", auth, middleware.RequirePermission(models.PermUserAudit), h.LoginHistory)
 }
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req service.RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request body",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	user, err := h.AuthService.Register(c.Context(), req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(), // error jelas dari service
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"user": user,
+			"user": dto.NewUserResponse(user),
 		},
 	})
 }
 
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req service.LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request body",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.AuthService.Login(c.Context(), req, clientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	// Berisi token, atau challenge_token bila 2FA diperlukan
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+// VerifyTwoFactor handles POST /auth/2fa/verify
+func (h *AuthHandler) VerifyTwoFactor(c *fiber.Ctx) error {
+	var req service.TwoFactorChallengeRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.AuthService.VerifyTwoFactor(c.Context(), req, clientInfo(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+// SetupTwoFactor handles POST /auth/2fa/setup (enrolment forced at login)
+func (h *AuthHandler) SetupTwoFactor(c *fiber.Ctx) error {
+	var req service.TwoFactorSetupRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	setup, err := h.AuthService.SetupTwoFactorChallenge(c.Context(), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   setup,
+	})
+}
+
+// Refresh handles POST /auth/refresh
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req service.RefreshRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	tokens, err := h.AuthService.Refresh(c.Context(), req, clientInfo(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   tokens,
+	})
+}
+
+// Logout handles POST /auth/logout
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req service.RefreshRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.AuthService.Logout(c.Context(), req); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Logged out",
+	})
+}
+
+// ChangePassword handles POST /auth/change-password
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	var req service.ChangePasswordRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	userID := c.Locals("user_id").(uint)
+	sessionID, _ := c.Locals("session_id").(string)
+	if err := h.AuthService.ChangePassword(c.Context(), userID, sessionID, req); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Password changed, other sessions have been logged out",
+	})
+}
+
+// ForgotPassword handles POST /auth/forgot-password
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req service.ForgotPasswordRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.AuthService.ForgotPassword(c.Context(), req); err != nil {
+		return err
+	}
+
+	// Respons sama untuk email terdaftar maupun tidak
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "If the email is registered, a reset link has been sent",
+	})
+}
+
+// ResetPassword handles POST /auth/reset-password
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req service.ResetPasswordRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.AuthService.ResetPassword(c.Context(), req); err != nil {
+		return err
 	}
 
-	token, err := h.AuthService.Login(c.Context(), req)
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Password has been reset, please log in again",
+	})
+}
+
+// UnlockUser handles POST /admin/users/:id/unlock
+func (h *AuthHandler) UnlockUser(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(), // error jelas dari service
-		})
+		return apperr.BadRequest("Invalid user ID")
 	}
 
+	if err := h.AuthService.UnlockAccount(c.Context(), uint(id64)); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Account unlocked",
+	})
+}
+
+// LoginHistory handles GET /admin/users/:id/logins
+func (h *AuthHandler) LoginHistory(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid user ID")
+	}
+
+	list, err := h.AuthService.LoginHistory(c.Context(), uint(id64), c.QueryInt("limit", 50))
+	if err != nil {
+		return err
+	}
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"token": token,
+			"logins": list,
 		},
 	})
 }
+
+// clientInfo captures the device details stored on the session
+func clientInfo(c *fiber.Ctx) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
+}
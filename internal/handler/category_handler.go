@@ -3,7 +3,10 @@ package handler
 import (
 	"strconv"
 
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/dto"
 	"FinalTask/internal/middleware"
+	"FinalTask/internal/models"
 	"FinalTask/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,39 +16,35 @@ type CategoryHandler struct {
 	CategoryService service.CategoryService
 }
 
-func NewCategoryHandler(r fiber.Router, catService service.CategoryService) {
+func NewCategoryHandler(r fiber.Router, auth fiber.Handler, catService service.CategoryService) {
 	h := &CategoryHandler{CategoryService: catService}
-	group := r.Group("/categories", middleware.JWTProtected(), middleware.AdminOnly())
-
-	group.Post("", h.CreateCategory)
-	group.Get("", h.ListCategory)
-	group.Get("/:id", h.GetCategoryByID)
-	group.Put("/:id", h.UpdateCategory)
-	group.Delete("/:id", h.DeleteCategory)
+	group := r.Group("/categories", auth)
+
+	canRead := middleware.RequirePermission(models.PermCategoryRead)
+	canWrite := middleware.RequirePermission(models.PermCategoryWrite)
+	group.Post("", canWrite, h.CreateCategory)
+	group.Get("", canRead, h.ListCategory)
+	group.Get("/:id", canRead, h.GetCategoryByID)
+	group.Put("/:id", canWrite, h.UpdateCategory)
+	group.Delete("/:id", canWrite, h.DeleteCategory)
 }
 
 // CreateCategory handles POST /categories
 func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
 	var req service.CreateCategoryRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request payload",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	cat, err := h.CategoryService.Create(c.Context(), req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"category": cat,
+			"category": dto.NewCategoryResponse(cat),
 		},
 	})
 }
@@ -54,16 +53,13 @@ func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
 func (h *CategoryHandler) ListCategory(c *fiber.Ctx) error {
 	list, err := h.CategoryService.List(c.Context())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Failed to retrieve categories",
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"categories": list,
+			"categories": dto.NewCategoryResponses(list),
 		},
 	})
 }
@@ -73,23 +69,17 @@ func (h *CategoryHandler) GetCategoryByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid category ID",
-		})
+		return apperr.BadRequest("Invalid category ID")
 	}
 	id := uint(id64)
 
 	cat, err := h.CategoryService.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
-		"data":   fiber.Map{"category": cat},
+		"data":   fiber.Map{"category": dto.NewCategoryResponse(cat)},
 	})
 }
 
@@ -98,33 +88,24 @@ func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid category ID",
-		})
+		return apperr.BadRequest("Invalid category ID")
 	}
 	id := uint(id64)
 
 	var req service.UpdateCategoryRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request payload",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	updated, err := h.CategoryService.Update(c.Context(), id, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"category": updated,
+			"category": dto.NewCategoryResponse(updated),
 		},
 	})
 }
@@ -134,18 +115,12 @@ func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid category ID",
-		})
+		return apperr.BadRequest("Invalid category ID")
 	}
 	id := uint(id64)
 
 	if err := h.CategoryService.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusNoContent).JSON(fiber.Map{
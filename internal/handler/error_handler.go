@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"FinalTask/internal/apperr"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Status  string      `json:"status"` // "fail" (4xx) atau "error" (5xx)
+	Code    apperr.Code `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details"`
+}
+
+// ErrorHandler is the Fiber ErrorHandler. Handlers and middleware just
+// return the error: *apperr.Error keeps its code and message, *fiber.Error
+// (unknown route, bad body, ...) keeps its status, anything else is a 500
+// whose detail is only logged.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	res := ErrorResponse{
+		Code:    apperr.CodeInternal,
+		Message: "terjadi kesalahan pada server",
+	}
+	status := fiber.StatusInternalServerError
+
+	var fe *fiber.Error
+	if e, ok := apperr.As(err); ok {
+		res.Code, res.Message, res.Details = e.Code, e.Message, e.Details
+		status = e.Code.Status()
+		if e.RetryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(e.RetryAfter.Seconds())+1))
+		}
+	} else if errors.As(err, &fe) {
+		status = fe.Code
+		res.Code = apperr.CodeForStatus(fe.Code)
+		res.Message = fe.Message
+	}
+
+	res.Status = "fail"
+	if status >= fiber.StatusInternalServerError {
+		res.Status = "error"
+		log.Printf("❌ %s %s: %v", c.Method(), c.Path(), err)
+	}
+	return c.Status(status).JSON(res)
+}
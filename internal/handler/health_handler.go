@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/migrations"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type HealthHandler struct {
+	DB *gorm.DB
+	// CheckMigrations is false in AutoMigrate dev mode, where
+	// schema_migrations is never written
+	CheckMigrations bool
+}
+
+func NewHealthHandler(r fiber.Router, db *gorm.DB, checkMigrations bool) {
+	h := &HealthHandler{DB: db, CheckMigrations: checkMigrations}
+
+	r.Get("/healthz", h.Liveness)
+	r.Get("/readyz", h.Readiness)
+}
+
+// Liveness handles GET /healthz: the process is up and serving requests
+func (h *HealthHandler) Liveness(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status": "ok",
+	})
+}
+
+// Readiness handles GET /readyz: the database answers and the schema is current
+func (h *HealthHandler) Readiness(c *fiber.Ctx) error {
+	checks := fiber.Map{}
+	ready := true
+
+	ctx, cancel := context.WithTimeout(c.Context(), 2*time.Second)
+	defer cancel()
+
+	sqlDB, err := h.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		ready = false
+		checks["database"] = err.Error()
+	} else {
+		checks["database"] = "ok"
+	}
+
+	if h.CheckMigrations && err == nil {
+		pending, err := migrations.Pending(h.DB.WithContext(ctx))
+		switch {
+		case err != nil:
+			ready = false
+			checks["migrations"] = err.Error()
+		case len(pending) > 0:
+			ready = false
+			checks["migrations"] = fiber.Map{"pending": pending}
+		default:
+			checks["migrations"] = "ok"
+		}
+	}
+
+	if !ready {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"status": "unavailable",
+			"checks": checks,
+		})
+	}
+	return c.JSON(fiber.Map{
+		"status": "ok",
+		"checks": checks,
+	})
+}
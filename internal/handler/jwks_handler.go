@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"FinalTask/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSHandler struct {
+	Keys *utils.JWTKeys
+}
+
+func NewJWKSHandler(r fiber.Router, keys *utils.JWTKeys) {
+	h := &JWKSHandler{Keys: keys}
+
+	r.Get("/.well-known/jwks.json", h.JWKS)
+}
+
+// JWKS handles GET /.well-known/jwks.json: public keys (RS256/EdDSA) that
+// verify access tokens, matched by the kid header. Empty with plain HS256.
+func (h *JWKSHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.Keys.JWKS())
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OIDCHandler struct {
+	AuthService service.AuthService
+}
+
+func NewOIDCHandler(r fiber.Router, authService service.AuthService) {
+	h := &OIDCHandler{AuthService: authService}
+
+	// "Login with ...": browser diarahkan ke /login, provider kembali ke /callback
+	group := r.Group("/auth/oidc")
+	group.Get("/providers", h.Providers)
+	group.Get("/:provider/login", h.Login)
+	group.Get("/:provider/callback", h.Callback)
+}
+
+// Providers handles GET /auth/oidc/providers
+func (h *OIDCHandler) Providers(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   h.AuthService.OIDCProviders(),
+	})
+}
+
+// Login handles GET /auth/oidc/:provider/login: redirect ke halaman login provider
+func (h *OIDCHandler) Login(c *fiber.Ctx) error {
+	url, err := h.AuthService.OIDCAuthURL(c.Context(), c.Params("provider"))
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Redirect(url, fiber.StatusFound)
+}
+
+// Callback handles GET /auth/oidc/:provider/callback?code=...&state=...
+// Responsnya sama dengan /auth/login: token, atau challenge 2FA
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	var req service.OIDCCallbackRequest
+	if err := c.QueryParser(&req); err != nil {
+		return apperr.BadRequest("Invalid callback parameters")
+	}
+	result, err := h.AuthService.OIDCCallback(c.Context(), c.Params("provider"), req, clientInfo(c))
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   result,
+	})
+}
@@ -3,7 +3,10 @@ package handler
 import (
 	"strconv"
 
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/dto"
 	"FinalTask/internal/middleware"
+	"FinalTask/internal/models"
 	"FinalTask/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,39 +16,38 @@ type ProductHandler struct {
 	ProductService service.ProductService
 }
 
-func NewProductHandler(r fiber.Router, prodService service.ProductService) {
+// NewProductHandler registers /products. auth accepts a user JWT or a store
+// API key; API keys additionally need the matching products:* scope.
+func NewProductHandler(r fiber.Router, auth fiber.Handler, prodService service.ProductService) {
 	h := &ProductHandler{ProductService: prodService}
-	group := r.Group("/products", middleware.JWTProtected())
-
-	group.Post("", h.CreateProduct)
-	group.Get("", h.ListProduct)
-	group.Get("/:id", h.GetProduct)
-	group.Put("/:id", h.UpdateProduct)
-	group.Delete("/:id", h.DeleteProduct)
-	group.Post("/:id/upload", h.UploadProductImage)
+	group := r.Group("/products", auth)
+	canRead := middleware.RequireScope(models.ScopeProductsRead)
+	canWrite := middleware.RequireScope(models.ScopeProductsWrite)
+
+	group.Post("", canWrite, h.CreateProduct)
+	group.Get("", canRead, h.ListProduct)
+	group.Get("/:id", canRead, h.GetProduct)
+	group.Get("/:id/versions", canRead, h.ListProductVersions)
+	group.Put("/:id", canWrite, h.UpdateProduct)
+	group.Delete("/:id", canWrite, h.DeleteProduct)
+	group.Post("/:id/upload", canWrite, h.UploadProductImage)
 }
 
 // CreateProduct handles POST /products
 func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 	var req service.CreateProductRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request payload",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 	prod, err := h.ProductService.Create(c.Context(), userID, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"product": prod,
+			"product": dto.NewProductResponse(prod),
 		},
 	})
 }
@@ -55,15 +57,12 @@ func (h *ProductHandler) ListProduct(c *fiber.Ctx) error {
 	qs := c.Queries()
 	list, err := h.ProductService.List(c.Context(), qs)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"products": list,
+			"products": dto.NewProductResponses(list),
 		},
 	})
 }
@@ -73,59 +72,70 @@ func (h *ProductHandler) GetProduct(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid product ID",
-		})
+		return apperr.BadRequest("Invalid product ID")
 	}
 	id := uint(id64)
 
-	prod, err := h.ProductService.GetByID(c.Context(), id)
+	prod, err := h.ProductService.GetByID(c.Context(), productViewer(c), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"product": prod,
+			"product": dto.NewProductResponse(prod),
 		},
 	})
 }
 
+// ListProductVersions handles GET /products/:id/versions
+func (h *ProductHandler) ListProductVersions(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid product ID")
+	}
+	prod, logs, err := h.ProductService.ListVersions(c.Context(), productViewer(c), uint(id64))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"versions": dto.NewProductVersionResponses(prod, logs),
+		},
+	})
+}
+
+// productViewer: staff = token membawa store:read (API key tidak punya permission)
+func productViewer(c *fiber.Ctx) service.ProductViewer {
+	return service.ProductViewer{
+		UserID: c.Locals("user_id").(uint),
+		Staff:  middleware.HasPermission(c, models.PermStoreRead),
+	}
+}
+
 // UpdateProduct handles PUT /products/:id
 func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid product ID",
-		})
+		return apperr.BadRequest("Invalid product ID")
 	}
 	id := uint(id64)
 
 	var req service.CreateProductRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request payload",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 	updated, err := h.ProductService.Update(c.Context(), userID, id, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"product": updated,
+			"product": dto.NewProductResponse(updated),
 		},
 	})
 }
@@ -136,47 +146,33 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid product ID",
-		})
+		return apperr.BadRequest("Invalid product ID")
 	}
 	id := uint(id64)
 
 	if err := h.ProductService.Delete(c.Context(), userID, id); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.Status(fiber.StatusNoContent).JSON(nil)
 }
 
 // UploadProductImage handles POST /products/:id/upload
 func (h *ProductHandler) UploadProductImage(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid product ID",
-		})
+		return apperr.BadRequest("Invalid product ID")
 	}
 	id := uint(id64)
 
 	file, err := c.FormFile("file")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "File is required",
-		})
+		return apperr.BadRequest("File is required")
 	}
-	url, err := h.ProductService.UploadImage(c.Context(), id, file)
+	url, err := h.ProductService.UploadImage(c.Context(), userID, id, file)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
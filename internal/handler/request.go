@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/service"
+	"FinalTask/internal/validate"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestBodies lists every type handlers pass to parseBody; add new
+// request DTOs here so their tags are checked by CheckRequestBodies
+var requestBodies = []interface{}{
+	service.RegisterRequest{},
+	service.LoginRequest{},
+	service.ChangePasswordRequest{},
+	service.ForgotPasswordRequest{},
+	service.ResetPasswordRequest{},
+	service.RefreshRequest{},
+	service.TwoFactorChallengeRequest{},
+	service.TwoFactorSetupRequest{},
+	service.TwoFactorCodeRequest{},
+	service.DisableTwoFactorRequest{},
+	service.SecurityPolicy{},
+	service.VerifyRequest{},
+	service.SetPhoneRequest{},
+	service.RoleRequest{},
+	service.AssignRoleRequest{},
+	service.CreateAPIKeyRequest{},
+	service.UpdateUserRequest{},
+	service.CreateAddressRequest{},
+	service.CreateCategoryRequest{},
+	service.UpdateCategoryRequest{},
+	service.UpdateStoreRequest{},
+	service.CreateProductRequest{},
+	service.CreateTransactionRequest{},
+	service.UpdateOrderStatusRequest{},
+	service.CancelOrderRequest{},
+}
+
+// CheckRequestBodies parses the validate tags of every request DTO once, so
+// a malformed tag stops the app at startup instead of failing a request
+func CheckRequestBodies() error {
+	return validate.Register(requestBodies...)
+}
+
+// parseBody decodes the JSON body into out and checks its validate tags, so
+// the service only ever sees a well-formed request (422 otherwise)
+func parseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		// JSON valid tapi tipe field salah (mis. harga "15.000"): laporkan per field
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			message := "tipe data tidak sesuai"
+			if typeErr.Type == reflect.TypeOf(models.Money(0)) {
+				message = "harus berupa angka rupiah bulat tanpa titik atau koma"
+			}
+			return apperr.InvalidField(typeErr.Field, message)
+		}
+		return apperr.BadRequest("Invalid request payload")
+	}
+	return validate.Struct(out)
+}
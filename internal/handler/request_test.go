@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"FinalTask/internal/models"
+	"FinalTask/internal/service"
+)
+
+// Every request DTO must have well-formed validate tags
+func TestRequestBodyTags(t *testing.T) {
+	if err := CheckRequestBodies(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The scopes tag repeats models.APIKeyScopes; a new scope must be added to both
+func TestAPIKeyScopesTag(t *testing.T) {
+	sf, _ := reflect.TypeOf(service.CreateAPIKeyRequest{}).FieldByName("Scopes")
+	_, allowed, _ := strings.Cut(sf.Tag.Get("validate"), "oneof=")
+	if got, want := allowed, strings.Join(models.APIKeyScopes, " "); got != want {
+		t.Errorf("scopes oneof = %q, want %q", got, want)
+	}
+}
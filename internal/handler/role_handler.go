@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"strconv"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/middleware"
+	"FinalTask/internal/models"
+	"FinalTask/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleHandler struct {
+	RoleService service.RoleService
+}
+
+func NewRoleHandler(r fiber.Router, auth fiber.Handler, roleService service.RoleService) {
+	h := &RoleHandler{RoleService: roleService}
+	canManage := middleware.RequirePermission(models.PermRoleManage)
+
+	r.Get("/admin/permissions", auth, canManage, h.ListPermissions)
+
+	roles := r.Group("/admin/roles", auth, canManage)
+	roles.Get("", h.ListRoles)
+	roles.Post("", h.CreateRole)
+	roles.Put("/:id", h.UpdateRole)
+	roles.Delete("/:id", h.DeleteRole)
+
+	// Penugasan role ke user
+	r.Get("/admin/users/:id/roles", auth, canManage, h.ListUserRoles)
+	r.Post("/admin/users/:id/roles", auth, canManage, h.AssignRole)
+	r.Delete("/admin/users/:id/roles/:role", auth, canManage, h.RemoveRole)
+}
+
+// ListRoles handles GET /admin/roles
+func (h *RoleHandler) ListRoles(c *fiber.Ctx) error {
+	list, err := h.RoleService.ListRoles(c.Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"roles": list,
+		},
+	})
+}
+
+// ListPermissions handles GET /admin/permissions
+func (h *RoleHandler) ListPermissions(c *fiber.Ctx) error {
+	list, err := h.RoleService.ListPermissions(c.Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"permissions": list,
+		},
+	})
+}
+
+// CreateRole handles POST /admin/roles
+func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
+	var req service.RoleRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	role, err := h.RoleService.CreateRole(c.Context(), req)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"role": role,
+		},
+	})
+}
+
+// UpdateRole handles PUT /admin/roles/:id
+func (h *RoleHandler) UpdateRole(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid role ID")
+	}
+	var req service.RoleRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	role, err := h.RoleService.UpdateRole(c.Context(), uint(id64), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"role": role,
+		},
+	})
+}
+
+// DeleteRole handles DELETE /admin/roles/:id
+func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid role ID")
+	}
+	if err := h.RoleService.DeleteRole(c.Context(), uint(id64)); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Role deleted",
+	})
+}
+
+// ListUserRoles handles GET /admin/users/:id/roles
+func (h *RoleHandler) ListUserRoles(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid user ID")
+	}
+	list, err := h.RoleService.UserRoles(c.Context(), uint(id64))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"roles": list,
+		},
+	})
+}
+
+// AssignRole handles POST /admin/users/:id/roles
+func (h *RoleHandler) AssignRole(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid user ID")
+	}
+	var req service.AssignRoleRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	if err := h.RoleService.AssignRole(c.Context(), uint(id64), req); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Role assigned, effective from the user's next login or token refresh",
+	})
+}
+
+// RemoveRole handles DELETE /admin/users/:id/roles/:role
+func (h *RoleHandler) RemoveRole(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid user ID")
+	}
+	if err := h.RoleService.RemoveRole(c.Context(), uint(id64), c.Params("role")); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Role removed, the user has been logged out",
+	})
+}
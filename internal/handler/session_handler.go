@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"strconv"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/middleware"
+	"FinalTask/internal/models"
+	"FinalTask/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	SessionService service.SessionService
+}
+
+func NewSessionHandler(r fiber.Router, auth fiber.Handler, sessionService service.SessionService) {
+	h := &SessionHandler{SessionService: sessionService}
+
+	// Sesi milik user yang sedang login
+	r.Get("/me/sessions", auth, h.ListMySessions)
+	r.Delete("/me/sessions/:id", auth, h.RevokeMySession)
+
+	// Admin: logout user dari semua perangkat
+	r.Delete("/admin/users/:id/sessions", auth, middleware.RequirePermission(models.PermSessionRevoke), h.RevokeUserSessions)
+}
+
+// ListMySessions handles GET /me/sessions
+func (h *SessionHandler) ListMySessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	sessionID, _ := c.Locals("session_id").(string)
+
+	list, err := h.SessionService.List(c.Context(), userID, sessionID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"sessions": list,
+		},
+	})
+}
+
+// RevokeMySession handles DELETE /me/sessions/:id
+func (h *SessionHandler) RevokeMySession(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	if err := h.SessionService.Revoke(c.Context(), userID, c.Params("id")); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Session revoked",
+	})
+}
+
+// RevokeUserSessions handles DELETE /admin/users/:id/sessions
+func (h *SessionHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id64, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid user ID")
+	}
+
+	if err := h.SessionService.RevokeAll(c.Context(), uint(id64)); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "All sessions of the user have been revoked",
+	})
+}
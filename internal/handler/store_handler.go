@@ -3,7 +3,10 @@ package handler
 import (
 	"strconv"
 
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/dto"
 	"FinalTask/internal/middleware"
+	"FinalTask/internal/models"
 	"FinalTask/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,18 +16,18 @@ type StoreHandler struct {
 	StoreService service.StoreService
 }
 
-func NewStoreHandler(r fiber.Router, storeService service.StoreService) {
+func NewStoreHandler(r fiber.Router, auth fiber.Handler, storeService service.StoreService) {
 	h := &StoreHandler{StoreService: storeService}
 
 	// --- My store endpoints (user must be logged in) ---
-	storeGroup := r.Group("/store", middleware.JWTProtected())
+	storeGroup := r.Group("/store", auth)
 	storeGroup.Get("", h.GetMyStore)    // GET  /store
 	storeGroup.Put("", h.UpdateMyStore) // PUT  /store
 
 	// --- Public/Admin endpoints under /stores ---
-	storesGroup := r.Group("/stores", middleware.JWTProtected())
-	// AdminOnly: only admin can list all or get any store
-	storesGroup.Use(middleware.AdminOnly())
+	storesGroup := r.Group("/stores", auth)
+	// store:read: only staff can list all or get any store
+	storesGroup.Use(middleware.RequirePermission(models.PermStoreRead))
 	storesGroup.Get("", h.GetAllStores)     // GET  /stores
 	storesGroup.Get("/:id", h.GetStoreByID) // GET  /stores/:id
 }
@@ -33,15 +36,12 @@ func (h *StoreHandler) GetMyStore(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 	store, err := h.StoreService.GetByUser(c.Context(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "store not found",
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"store": store,
+			"store": dto.NewStoreResponse(store),
 		},
 	})
 }
@@ -49,23 +49,17 @@ func (h *StoreHandler) GetMyStore(c *fiber.Ctx) error {
 func (h *StoreHandler) UpdateMyStore(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 	var req service.UpdateStoreRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "invalid request payload",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 	updated, err := h.StoreService.Update(c.Context(), userID, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"store": updated,
+			"store": dto.NewStoreResponse(updated),
 		},
 	})
 }
@@ -73,15 +67,12 @@ func (h *StoreHandler) UpdateMyStore(c *fiber.Ctx) error {
 func (h *StoreHandler) GetAllStores(c *fiber.Ctx) error {
 	list, err := h.StoreService.ListAll(c.Context())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "failed to retrieve stores",
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"stores": list,
+			"stores": dto.NewStoreResponses(list),
 		},
 	})
 }
@@ -90,24 +81,18 @@ func (h *StoreHandler) GetStoreByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "invalid store ID",
-		})
+		return apperr.BadRequest("invalid store ID")
 	}
 	id := uint(id64)
 
 	store, err := h.StoreService.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "store not found",
-		})
+		return err
 	}
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"store": store,
+			"store": dto.NewStoreResponse(store),
 		},
 	})
 }
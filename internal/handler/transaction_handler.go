@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"context"
 	"strconv"
 
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/dto"
 	"FinalTask/internal/middleware"
+	"FinalTask/internal/models"
 	"FinalTask/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,35 +17,62 @@ type TransactionHandler struct {
 	TrxService service.TransactionService
 }
 
-func NewTransactionHandler(r fiber.Router, trxService service.TransactionService) {
+// NewTransactionHandler registers /transactions behind integrationAuth (user
+// JWT or store API key with orders:* scopes) and the staff routes behind auth
+func NewTransactionHandler(r fiber.Router, auth, integrationAuth fiber.Handler, trxService service.TransactionService) {
 	h := &TransactionHandler{TrxService: trxService}
-	group := r.Group("/transactions", middleware.JWTProtected())
-	group.Post("", h.CreateTransaction)
-	group.Get("", h.ListTransactions)
-	group.Get("/:id", h.GetTransaction)
+	group := r.Group("/transactions", integrationAuth)
+	// Checkout selalu atas nama user, API key toko tidak bisa belanja
+	group.Post("", middleware.UserOnly(), h.CreateTransaction)
+	group.Get("", middleware.RequireScope(models.ScopeOrdersRead), h.ListTransactions)
+	group.Get("/:id", middleware.RequireScope(models.ScopeOrdersRead), h.GetTransaction)
+	// Buyer, seller (toko pemilik semua item) atau staff order:manage
+	group.Patch("/:id/status", middleware.RequireScope(models.ScopeOrdersWrite), h.UpdateTransactionStatus)
+	// Pembatalan sebelum pengiriman: cancel oleh pembeli, reject oleh penjual
+	group.Post("/:id/cancel", middleware.RequireScope(models.ScopeOrdersWrite), h.CancelTransaction)
+	group.Post("/:id/reject", middleware.RequireScope(models.ScopeOrdersWrite), h.RejectTransaction)
+
+	// Penjual login dengan JWT: pesanan yang masuk ke tokonya sendiri
+	storeGroup := r.Group("/store/transactions", auth)
+	storeGroup.Get("", h.ListStoreTransactions)
+	storeGroup.Get("/:id", h.GetStoreTransaction)
+
+	// Staff dengan order:read (mis. role support) bisa melihat semua transaksi
+	canRead := middleware.RequirePermission(models.PermOrderRead)
+	r.Get("/admin/transactions", auth, canRead, h.ListAllTransactions)
+	r.Get("/admin/transactions/:id", auth, canRead, h.GetAnyTransaction)
 }
 
 func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 	var req service.CreateTransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return fiber.ErrBadRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
-	trx, err := h.TrxService.Create(c.Context(), userID, req)
+	orders, err := h.TrxService.Create(c.Context(), userID, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
-	return c.JSON(trx)
+	return c.JSON(dto.NewCheckoutResponse(orders))
 }
 
+// ListTransactions returns the user's purchases, or for a store API key the
+// orders placed with that store (JWT sellers: GET /store/transactions)
 func (h *TransactionHandler) ListTransactions(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(uint)
 	qs := c.Queries()
+	if middleware.IsAPIKey(c) {
+		list, err := h.TrxService.ListByStore(c.Context(), c.Locals("store_id").(uint), qs)
+		if err != nil {
+			return err
+		}
+		return c.JSON(dto.NewTransactionResponses(list))
+	}
+	userID := c.Locals("user_id").(uint)
 	list, err := h.TrxService.List(c.Context(), userID, qs)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
-	return c.JSON(list)
+	return c.JSON(dto.NewTransactionResponses(list))
 }
 
 func (h *TransactionHandler) GetTransaction(c *fiber.Ctx) error {
@@ -50,13 +81,118 @@ func (h *TransactionHandler) GetTransaction(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id64, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+		return apperr.BadRequest("Invalid transaction ID")
 	}
 	id := uint(id64)
 
-	trx, err := h.TrxService.GetByID(c.Context(), userID, id)
+	var trx *models.Trx
+	if middleware.IsAPIKey(c) {
+		trx, err = h.TrxService.GetByStore(c.Context(), c.Locals("store_id").(uint), id)
+	} else {
+		trx, err = h.TrxService.GetByID(c.Context(), userID, id)
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(dto.NewTransactionResponse(trx))
+}
+
+// UpdateTransactionStatus handles PATCH /transactions/:id/status
+func (h *TransactionHandler) UpdateTransactionStatus(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid transaction ID")
+	}
+	var req service.UpdateOrderStatusRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	trx, err := h.TrxService.UpdateStatus(c.Context(), orderActor(c), uint(id64), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(dto.NewTransactionResponse(trx))
+}
+
+// CancelTransaction handles POST /transactions/:id/cancel
+func (h *TransactionHandler) CancelTransaction(c *fiber.Ctx) error {
+	return h.cancelOrReject(c, h.TrxService.Cancel)
+}
+
+// RejectTransaction handles POST /transactions/:id/reject
+func (h *TransactionHandler) RejectTransaction(c *fiber.Ctx) error {
+	return h.cancelOrReject(c, h.TrxService.Reject)
+}
+
+func (h *TransactionHandler) cancelOrReject(c *fiber.Ctx, fn func(context.Context, service.OrderActor, uint, service.CancelOrderRequest) (*models.Trx, error)) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid transaction ID")
+	}
+	var req service.CancelOrderRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	trx, err := fn(c.Context(), orderActor(c), uint(id64), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(dto.NewTransactionResponse(trx))
+}
+
+// orderActor: staff = token membawa order:manage, API key hanya penjual
+func orderActor(c *fiber.Ctx) service.OrderActor {
+	actor := service.OrderActor{
+		UserID: c.Locals("user_id").(uint),
+		Staff:  middleware.HasPermission(c, models.PermOrderManage),
+	}
+	if middleware.IsAPIKey(c) {
+		actor.StoreID = c.Locals("store_id").(uint)
+	}
+	return actor
+}
+
+// ListStoreTransactions handles GET /store/transactions, the seller view of
+// /transactions for a user JWT. Store API keys get it on /transactions.
+func (h *TransactionHandler) ListStoreTransactions(c *fiber.Ctx) error {
+	list, err := h.TrxService.ListForSeller(c.Context(), c.Locals("user_id").(uint), c.Queries())
+	if err != nil {
+		return err
+	}
+	return c.JSON(dto.NewTransactionResponses(list))
+}
+
+// GetStoreTransaction handles GET /store/transactions/:id
+func (h *TransactionHandler) GetStoreTransaction(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid transaction ID")
+	}
+	trx, err := h.TrxService.GetForSeller(c.Context(), c.Locals("user_id").(uint), uint(id64))
+	if err != nil {
+		return err
+	}
+	return c.JSON(dto.NewTransactionResponse(trx))
+}
+
+// ListAllTransactions handles GET /admin/transactions
+func (h *TransactionHandler) ListAllTransactions(c *fiber.Ctx) error {
+	list, err := h.TrxService.ListAll(c.Context(), c.Queries())
+	if err != nil {
+		return err
+	}
+	return c.JSON(dto.NewTransactionResponses(list))
+}
+
+// GetAnyTransaction handles GET /admin/transactions/:id
+func (h *TransactionHandler) GetAnyTransaction(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid transaction ID")
+	}
+	trx, err := h.TrxService.GetAnyByID(c.Context(), uint(id64))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
-	return c.JSON(trx)
+	return c.JSON(dto.NewTransactionResponse(trx))
 }
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"strconv"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/middleware"
+	"FinalTask/internal/models"
+	"FinalTask/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TwoFactorHandler struct {
+	TwoFactorService service.TwoFactorService
+}
+
+func NewTwoFactorHandler(r fiber.Router, auth fiber.Handler, twoFactorService service.TwoFactorService) {
+	h := &TwoFactorHandler{TwoFactorService: twoFactorService}
+
+	// 2FA milik user yang sedang login
+	me := r.Group("/me/2fa", auth)
+	me.Get("", h.Status)
+	me.Post("/setup", h.Setup)
+	me.Post("/enable", h.Enable)
+	me.Post("/disable", h.Disable)
+	me.Post("/recovery-codes", h.RegenerateRecoveryCodes)
+
+	// Admin: kebijakan wajib 2FA & reset 2FA user yang kehilangan perangkat
+	canManage := middleware.RequirePermission(models.PermSecurity)
+	r.Get("/admin/security-policy", auth, canManage, h.GetPolicy)
+	r.Put("/admin/security-policy", auth, canManage, h.UpdatePolicy)
+	r.Delete("/admin/users/:id/2fa", auth, canManage, h.ResetUser)
+}
+
+// Status handles GET /me/2fa
+func (h *TwoFactorHandler) Status(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	status, err := h.TwoFactorService.Status(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   status,
+	})
+}
+
+// Setup handles POST /me/2fa/setup: returns the secret and otpauth:// URI
+func (h *TwoFactorHandler) Setup(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	setup, err := h.TwoFactorService.Setup(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   setup,
+	})
+}
+
+// Enable handles POST /me/2fa/enable
+func (h *TwoFactorHandler) Enable(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	var req service.TwoFactorCodeRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	codes, err := h.TwoFactorService.Enable(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "2FA enabled, store the recovery codes now, they will not be shown again",
+		"data":    codes,
+	})
+}
+
+// Disable handles POST /me/2fa/disable
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	var req service.DisableTwoFactorRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	if err := h.TwoFactorService.Disable(c.Context(), userID, req); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "2FA disabled",
+	})
+}
+
+// RegenerateRecoveryCodes handles POST /me/2fa/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	var req service.TwoFactorCodeRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	codes, err := h.TwoFactorService.RegenerateRecoveryCodes(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Old recovery codes no longer work",
+		"data":    codes,
+	})
+}
+
+// GetPolicy handles GET /admin/security-policy
+func (h *TwoFactorHandler) GetPolicy(c *fiber.Ctx) error {
+	policy, err := h.TwoFactorService.Policy(c.Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   policy,
+	})
+}
+
+// UpdatePolicy handles PUT /admin/security-policy
+func (h *TwoFactorHandler) UpdatePolicy(c *fiber.Ctx) error {
+	var req service.SecurityPolicy
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	policy, err := h.TwoFactorService.SetPolicy(c.Context(), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   policy,
+	})
+}
+
+// ResetUser handles DELETE /admin/users/:id/2fa
+func (h *TwoFactorHandler) ResetUser(c *fiber.Ctx) error {
+	id64, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperr.BadRequest("Invalid user ID")
+	}
+	if err := h.TwoFactorService.Reset(c.Context(), uint(id64)); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "2FA has been reset and the user has been logged out",
+	})
+}
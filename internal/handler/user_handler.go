@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"FinalTask/internal/middleware"
+	"FinalTask/internal/dto"
 	"FinalTask/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,45 +11,39 @@ type UserHandler struct {
 	UserService service.UserService
 }
 
-func NewUserHandler(r fiber.Router, userService service.UserService) {
+func NewUserHandler(r fiber.Router, auth fiber.Handler, userService service.UserService) {
 	h := &UserHandler{UserService: userService}
-	r.Get("/me", middleware.JWTProtected(), h.GetProfile)
-	r.Put("/me", middleware.JWTProtected(), h.UpdateProfile)
+	r.Get("/me", auth, h.GetProfile)
+	r.Put("/me", auth, h.UpdateProfile)
 }
 
 func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 	user, err := h.UserService.GetByID(c.Context(), userID)
 	if err != nil {
-		return fiber.ErrNotFound
+		return err
 	}
-	return c.JSON(user)
+	return c.JSON(dto.NewUserResponse(user))
 }
 
 func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uint)
 	var req service.UpdateUserRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "invalid request payload",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	res, err := h.UserService.Update(c.Context(), userID, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "fail",
-			"message": err.Error(),
-		})
+		return err
 	}
 	// kembalikan ProvinceName & CityName
 	return c.JSON(fiber.Map{
 		"status": "success",
-		"data": fiber.Map{
-			"user":          res.User,
-			"province_name": res.ProvinceName,
-			"city_name":     res.CityName,
+		"data": dto.ProfileResponse{
+			User:         dto.NewUserResponse(res.User),
+			ProvinceName: res.ProvinceName,
+			CityName:     res.CityName,
 		},
 	})
 }
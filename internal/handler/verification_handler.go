@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"FinalTask/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type VerificationHandler struct {
+	VerificationService service.VerificationService
+}
+
+func NewVerificationHandler(r fiber.Router, auth fiber.Handler, verificationService service.VerificationService) {
+	h := &VerificationHandler{VerificationService: verificationService}
+
+	// :channel = email | phone
+	group := r.Group("/auth/verification", auth)
+	group.Get("", h.Status)
+	group.Put("/phone", h.SetPhone)
+	group.Post("/:channel/send", h.Send)
+	group.Post("/:channel/confirm", h.Confirm)
+}
+
+// Status handles GET /auth/verification
+func (h *VerificationHandler) Status(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	status, err := h.VerificationService.Status(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   status,
+	})
+}
+
+// Send handles POST /auth/verification/:channel/send
+func (h *VerificationHandler) Send(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	sent, err := h.VerificationService.Send(c.Context(), userID, c.Params("channel"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   sent,
+	})
+}
+
+// Confirm handles POST /auth/verification/:channel/confirm
+func (h *VerificationHandler) Confirm(c *fiber.Ctx) error {
+	var req service.VerifyRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	userID := c.Locals("user_id").(uint)
+	if err := h.VerificationService.Verify(c.Context(), userID, c.Params("channel"), req); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Verified",
+	})
+}
+
+// SetPhone handles PUT /auth/verification/phone
+func (h *VerificationHandler) SetPhone(c *fiber.Ctx) error {
+	var req service.SetPhoneRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	userID := c.Locals("user_id").(uint)
+	status, err := h.VerificationService.SetPhone(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   status,
+	})
+}
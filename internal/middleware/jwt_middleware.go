@@ -2,37 +2,65 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
-	"FinalTask/config"
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/repository"
+	"FinalTask/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-// JWTProtected validates JWT token and sets user_id and is_admin in context
-func JWTProtected() fiber.Handler {
+// JWTProtected validates JWT token (signature by kid, expiry, issuer and
+// audience), rejects tokens whose session has been revoked, and sets
+// user_id, roles, permissions, session_id and jti in context
+func JWTProtected(keys *utils.JWTKeys, sessions repository.SessionRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized, missing token"})
+			return apperr.Unauthorized("Unauthorized, missing token")
 		}
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token format"})
+			return apperr.Unauthorized("Invalid token format")
 		}
 		tokenStr := parts[1]
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-			return config.JwtSecret, nil
-		})
-		if err != nil || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+		claims, err := keys.ParseJWT(tokenStr)
+		if err != nil {
+			return apperr.Unauthorized("Invalid or expired token")
 		}
-		claims := token.Claims.(jwt.MapClaims)
+
+		// Token tanpa sesi aktif (logout, dicabut user/admin) ditolak
+		sessionID, _ := claims["sid"].(string)
+		if sessionID == "" {
+			return apperr.Unauthorized("Invalid or expired token")
+		}
+		session, err := sessions.FindByID(c.Context(), sessionID)
+		if err != nil || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+			return apperr.Unauthorized("Session has been revoked")
+		}
+
 		// set context locals
-		userID := uint(claims["user_id"].(float64))
-		isAdmin := claims["is_admin"].(bool)
+		userIDClaim, _ := claims["user_id"].(float64)
+		userID := uint(userIDClaim)
+		jti, _ := claims["jti"].(string)
 		c.Locals("user_id", userID)
-		c.Locals("is_admin", isAdmin)
+		c.Locals("roles", stringList(claims["roles"]))
+		c.Locals("permissions", stringList(claims["perms"]))
+		c.Locals("session_id", sessionID)
+		c.Locals("jti", jti)
 		return c.Next()
 	}
 }
+
+// stringList converts a JSON array claim into []string
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -1,16 +1,36 @@
 package middleware
 
 import (
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+
 	"github.com/gofiber/fiber/v2"
 )
 
-// AdminOnly allows access only to users with is_admin = true
-func AdminOnly() fiber.Handler {
+// RequirePermission allows access only when the token carries perm
+// (or the "*" permission of super-admin). Must run after JWTProtected.
+func RequirePermission(perm string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		isAdmin, ok := c.Locals("is_admin").(bool)
-		if !ok || !isAdmin {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Access denied, admin only"})
+		perms, _ := c.Locals("permissions").([]string)
+		if !hasPermission(perms, perm) {
+			return apperr.Forbidden("Access denied, missing permission " + perm)
 		}
 		return c.Next()
 	}
 }
+
+// HasPermission is the inline form of RequirePermission, for handlers whose
+// behaviour (not access) depends on a permission
+func HasPermission(c *fiber.Ctx, perm string) bool {
+	perms, _ := c.Locals("permissions").([]string)
+	return hasPermission(perms, perm)
+}
+
+func hasPermission(perms []string, perm string) bool {
+	for _, p := range perms {
+		if p == perm || p == models.PermAll {
+			return true
+		}
+	}
+	return false
+}
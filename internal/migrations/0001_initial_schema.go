@@ -0,0 +1,175 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Snapshot of the schema as it was created by AutoMigrate before versioned
+// migrations existed. These types are frozen on purpose: later model changes
+// get their own migration instead of editing this file.
+
+type userV1 struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement"`
+	Nama         string     `gorm:"size:255;not null"`
+	Password     string     `gorm:"size:255;not null"`
+	NoTelp       string     `gorm:"size:255;unique;not null"`
+	Email        string     `gorm:"size:255;unique;not null"`
+	TanggalLahir *time.Time `gorm:"type:date"`
+	JenisKelamin string     `gorm:"size:50"`
+	Tentang      string     `gorm:"type:text"`
+	Pekerjaan    string     `gorm:"size:255"`
+	IDProvinsi   string     `gorm:"size:255"`
+	IDKota       string     `gorm:"size:255"`
+	IsAdmin      bool       `gorm:"default:false"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Alamat []*alamatV1 `gorm:"foreignKey:IDUser"`
+	Toko   *tokoV1     `gorm:"foreignKey:IDUser"`
+	Trx    []*trxV1    `gorm:"foreignKey:IDUser"`
+}
+
+func (userV1) TableName() string { return "users" }
+
+type alamatV1 struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	IDUser       uint   `gorm:"not null"`
+	JudulAlamat  string `gorm:"size:255"`
+	NamaPenerima string `gorm:"size:255"`
+	NoTelp       string `gorm:"size:255"`
+	DetailAlamat string `gorm:"size:255"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (alamatV1) TableName() string { return "alamats" }
+
+type tokoV1 struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint   `gorm:"not null;unique"`
+	NamaToko  string `gorm:"size:255"`
+	URLFoto   string `gorm:"size:255"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Produk []produkV1 `gorm:"foreignKey:IDToko"`
+}
+
+func (tokoV1) TableName() string { return "tokos" }
+
+type categoryV1 struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	NamaCategory string `gorm:"size:255;not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Produk []produkV1 `gorm:"foreignKey:IDCategory"`
+}
+
+func (categoryV1) TableName() string { return "categories" }
+
+type produkV1 struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	NamaProduk    string `gorm:"size:255;not null"`
+	Slug          string `gorm:"size:255;unique;not null"`
+	HargaReseller string `gorm:"size:255;not null"`
+	HargaKonsumen string `gorm:"size:255;not null"`
+	Stok          int    `gorm:"not null"`
+	Deskripsi     string `gorm:"type:text"`
+	IDToko        uint   `gorm:"not null"`
+	IDCategory    uint   `gorm:"not null"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	FotoProduk []fotoProdukV1 `gorm:"foreignKey:IDProduk"`
+	LogProduk  []logProdukV1  `gorm:"foreignKey:IDProduk"`
+}
+
+func (produkV1) TableName() string { return "produks" }
+
+type fotoProdukV1 struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	IDProduk  uint   `gorm:"not null"`
+	URL       string `gorm:"size:255;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (fotoProdukV1) TableName() string { return "foto_produks" }
+
+type logProdukV1 struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement"`
+	IDProduk      uint      `gorm:"not null"`
+	NamaProduk    string    `gorm:"size:255;not null"`
+	Slug          string    `gorm:"size:255;not null"`
+	HargaReseller string    `gorm:"size:255;not null"`
+	HargaKonsumen string    `gorm:"size:255;not null"`
+	Deskripsi     string    `gorm:"type:text;not null"`
+	IDToko        uint      `gorm:"not null"`
+	IDCategory    uint      `gorm:"not null"`
+	StokAwal      int       `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (logProdukV1) TableName() string { return "log_produks" }
+
+type trxV1 struct {
+	ID               uint `gorm:"primaryKey"`
+	IDUser           uint
+	AlamatPengiriman uint
+	MethodBayar      string
+	HargaTotal       int
+	KodeInvoice      string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	DetailTrx []detailTrxV1 `gorm:"foreignKey:IDTrx"`
+}
+
+func (trxV1) TableName() string { return "trxes" }
+
+type detailTrxV1 struct {
+	ID          uint `gorm:"primaryKey;autoIncrement"`
+	IDTrx       uint `gorm:"not null"`
+	IDLogProduk uint `gorm:"not null"`
+	IDToko      uint `gorm:"not null"`
+	Kuantitas   int  `gorm:"not null"`
+	HargaTotal  int  `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (detailTrxV1) TableName() string { return "detail_trxes" }
+
+func init() {
+	register(Migration{
+		Version: "0001_initial_schema",
+		Up: func(tx *gorm.DB) error {
+			// Databases created by the old AutoMigrate boot already have
+			// these tables; create only what is missing so they can adopt
+			// versioned migrations without a manual step.
+			tables := []interface{}{
+				&userV1{}, &alamatV1{}, &tokoV1{}, &categoryV1{}, &produkV1{},
+				&fotoProdukV1{}, &logProdukV1{}, &trxV1{}, &detailTrxV1{},
+			}
+			for _, t := range tables {
+				if tx.Migrator().HasTable(t) {
+					continue
+				}
+				if err := tx.Migrator().CreateTable(t); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(
+				&detailTrxV1{}, &trxV1{}, &logProdukV1{}, &fotoProdukV1{},
+				&produkV1{}, &categoryV1{}, &tokoV1{}, &alamatV1{}, &userV1{},
+			)
+		},
+	})
+}
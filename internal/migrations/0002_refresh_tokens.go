@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type refreshTokenV2 struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint      `gorm:"not null;index"`
+	FamilyID  string    `gorm:"size:64;not null;index"`
+	TokenHash string    `gorm:"size:64;not null;unique"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (refreshTokenV2) TableName() string { return "refresh_tokens" }
+
+func init() {
+	register(Migration{
+		Version: "0002_refresh_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&refreshTokenV2{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&refreshTokenV2{})
+		},
+	})
+}
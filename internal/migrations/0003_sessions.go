@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type sessionV3 struct {
+	ID         string    `gorm:"primaryKey;size:36"`
+	IDUser     uint      `gorm:"not null;index"`
+	UserAgent  string    `gorm:"size:255"`
+	IPAddress  string    `gorm:"size:64"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastSeenAt time.Time `gorm:"not null"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (sessionV3) TableName() string { return "sessions" }
+
+func init() {
+	register(Migration{
+		Version: "0003_sessions",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&sessionV3{}); err != nil {
+				return err
+			}
+			// Refresh token lama tidak punya sesi, jadi dicabut; user cukup login ulang
+			return tx.Table("refresh_tokens").
+				Where("revoked_at IS NULL").
+				Update("revoked_at", time.Now()).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&sessionV3{})
+		},
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type passwordResetV4 struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;unique"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (passwordResetV4) TableName() string { return "password_resets" }
+
+func init() {
+	register(Migration{
+		Version: "0004_password_resets",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&passwordResetV4{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&passwordResetV4{})
+		},
+	})
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userV5 struct {
+	EmailVerifiedAt *time.Time
+	PhoneVerifiedAt *time.Time
+}
+
+func (userV5) TableName() string { return "users" }
+
+type verificationCodeV5 struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint      `gorm:"not null;index"`
+	Channel   string    `gorm:"size:16;not null"`
+	Target    string    `gorm:"size:255;not null"`
+	CodeHash  string    `gorm:"size:64;not null"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (verificationCodeV5) TableName() string { return "verification_codes" }
+
+func init() {
+	register(Migration{
+		Version: "0005_verification_codes",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"EmailVerifiedAt", "PhoneVerifiedAt"} {
+				if err := tx.Migrator().AddColumn(&userV5{}, field); err != nil {
+					return err
+				}
+			}
+			// Akun yang sudah ada dibuat sebelum verifikasi diwajibkan,
+			// jadi dianggap terverifikasi agar tidak tiba-tiba diblokir
+			now := time.Now()
+			if err := tx.Table("users").Where("email_verified_at IS NULL").Updates(map[string]any{
+				"email_verified_at": now,
+				"phone_verified_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&verificationCodeV5{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&verificationCodeV5{}); err != nil {
+				return err
+			}
+			// ALTER TABLE langsung: DropColumn GORM di sqlite membangun ulang
+			// tabel users dan gagal karena foreign key dari tabel lain
+			for _, column := range []string{"email_verified_at", "phone_verified_at"} {
+				if err := tx.Exec("ALTER TABLE users DROP COLUMN " + column).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userV6 struct {
+	FailedLoginCount  int `gorm:"not null;default:0"`
+	LastFailedLoginAt *time.Time
+	LockedUntil       *time.Time
+}
+
+func (userV6) TableName() string { return "users" }
+
+type loginAttemptV6 struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	IDUser    *uint     `gorm:"index"`
+	Email     string    `gorm:"size:255;not null;index"`
+	IPAddress string    `gorm:"size:64;not null;index:idx_login_attempts_ip_created"`
+	UserAgent string    `gorm:"size:255"`
+	Success   bool      `gorm:"not null;default:false"`
+	Reason    string    `gorm:"size:32;not null"`
+	CreatedAt time.Time `gorm:"index:idx_login_attempts_ip_created"`
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (loginAttemptV6) TableName() string { return "login_attempts" }
+
+func init() {
+	register(Migration{
+		Version: "0006_login_attempts",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"FailedLoginCount", "LastFailedLoginAt", "LockedUntil"} {
+				if err := tx.Migrator().AddColumn(&userV6{}, field); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().CreateTable(&loginAttemptV6{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&loginAttemptV6{}); err != nil {
+				return err
+			}
+			// Lihat 0005: DropColumn GORM di sqlite gagal karena foreign key
+			for _, column := range []string{"failed_login_count", "last_failed_login_at", "locked_until"} {
+				if err := tx.Exec("ALTER TABLE users DROP COLUMN " + column).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
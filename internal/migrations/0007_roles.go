@@ -0,0 +1,136 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type permissionV7 struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Name        string `gorm:"size:64;unique;not null"`
+	Description string `gorm:"size:255"`
+}
+
+func (permissionV7) TableName() string { return "permissions" }
+
+type roleV7 struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Name        string `gorm:"size:64;unique;not null"`
+	Description string `gorm:"size:255"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Permissions []*permissionV7 `gorm:"many2many:role_permissions;joinForeignKey:IDRole;joinReferences:IDPermission"`
+}
+
+func (roleV7) TableName() string { return "roles" }
+
+// CreateTable tidak membuat tabel join many2many, jadi didefinisikan sendiri
+type rolePermissionV7 struct {
+	IDRole       uint `gorm:"primaryKey"`
+	IDPermission uint `gorm:"primaryKey"`
+
+	Role       *roleV7       `gorm:"foreignKey:IDRole"`
+	Permission *permissionV7 `gorm:"foreignKey:IDPermission"`
+}
+
+func (rolePermissionV7) TableName() string { return "role_permissions" }
+
+type userRoleV7 struct {
+	IDUser    uint `gorm:"primaryKey"`
+	IDRole    uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+	Role *roleV7 `gorm:"foreignKey:IDRole"`
+}
+
+func (userRoleV7) TableName() string { return "user_roles" }
+
+// Permission & role bawaan; nama harus sama dengan konstanta di models/role.go
+var (
+	defaultPermissionsV7 = []permissionV7{
+		{Name: "*", Description: "Semua permission"},
+		{Name: "category:read", Description: "Lihat kategori"},
+		{Name: "category:write", Description: "Buat, ubah, hapus kategori"},
+		{Name: "store:read", Description: "Lihat semua toko"},
+		{Name: "order:read", Description: "Lihat transaksi semua user"},
+		{Name: "session:revoke", Description: "Cabut sesi user lain"},
+		{Name: "user:unlock", Description: "Buka kunci login user"},
+		{Name: "user:audit", Description: "Lihat audit login user"},
+		{Name: "role:manage", Description: "Kelola role & penugasan role"},
+	}
+	defaultRolesV7 = map[string][]string{
+		"super-admin": {"*"},
+		"support":     {"order:read", "store:read", "category:read", "user:audit"},
+	}
+	defaultRoleDescV7 = map[string]string{
+		"super-admin": "Akses penuh (pengganti is_admin)",
+		"support":     "Customer support, hanya baca",
+	}
+)
+
+// seedRolesV7 mengisi permission & role bawaan tanpa menimpa yang sudah ada,
+// lalu memindahkan user is_admin = true ke role super-admin
+func seedRolesV7(tx *gorm.DB) error {
+	for i := range defaultPermissionsV7 {
+		p := defaultPermissionsV7[i]
+		if err := tx.Where(permissionV7{Name: p.Name}).FirstOrCreate(&p).Error; err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	for _, name := range []string{"super-admin", "support"} {
+		role := roleV7{Name: name, Description: defaultRoleDescV7[name], CreatedAt: now, UpdatedAt: now}
+		if err := tx.Where(roleV7{Name: name}).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+		var perms []*permissionV7
+		if err := tx.Where("name IN ?", defaultRolesV7[name]).Find(&perms).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&role).Association("Permissions").Append(perms); err != nil {
+			return err
+		}
+	}
+
+	var superAdmin roleV7
+	if err := tx.Where("name = ?", "super-admin").First(&superAdmin).Error; err != nil {
+		return err
+	}
+	var adminIDs []uint
+	if err := tx.Table("users").Where("is_admin = ?", true).Pluck("id", &adminIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range adminIDs {
+		link := userRoleV7{IDUser: id, IDRole: superAdmin.ID, CreatedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	register(Migration{
+		Version: "0007_roles",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&permissionV7{}, &roleV7{}, &rolePermissionV7{}, &userRoleV7{}); err != nil {
+				return err
+			}
+			return seedRolesV7(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			// Satu per satu: DropTable dengan banyak model membalik urutannya
+			// sehingga tabel induk terhapus sebelum tabel join
+			for _, table := range []interface{}{&userRoleV7{}, &rolePermissionV7{}, &roleV7{}, &permissionV7{}} {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
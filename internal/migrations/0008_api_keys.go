@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyV8 struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	IDToko     uint      `gorm:"not null;index"`
+	IDUser     uint      `gorm:"not null"`
+	Name       string    `gorm:"size:100;not null"`
+	Prefix     string    `gorm:"size:16;not null"`
+	KeyHash    string    `gorm:"size:64;not null;unique"`
+	Scopes     string    `gorm:"size:255;not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastUsedAt *time.Time
+	LastUsedIP string `gorm:"size:64"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	Toko *tokoV1 `gorm:"foreignKey:IDToko"`
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (apiKeyV8) TableName() string { return "api_keys" }
+
+func init() {
+	register(Migration{
+		Version: "0008_api_keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&apiKeyV8{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&apiKeyV8{})
+		},
+	})
+}
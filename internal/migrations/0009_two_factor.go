@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userV9 struct {
+	TOTPSecret    string `gorm:"size:64"`
+	TOTPEnabledAt *time.Time
+	TOTPLastStep  int64 `gorm:"not null;default:0"`
+}
+
+func (userV9) TableName() string { return "users" }
+
+type recoveryCodeV9 struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (recoveryCodeV9) TableName() string { return "recovery_codes" }
+
+type loginChallengeV9 struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;unique"`
+	Purpose   string    `gorm:"size:16;not null"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (loginChallengeV9) TableName() string { return "login_challenges" }
+
+type settingV9 struct {
+	Name      string `gorm:"primaryKey;size:100"`
+	Value     string `gorm:"size:255;not null"`
+	UpdatedAt time.Time
+}
+
+func (settingV9) TableName() string { return "settings" }
+
+// seedSecurityPermissionV9 menambah permission security:manage (super-admin
+// sudah mendapatkannya lewat "*")
+func seedSecurityPermissionV9(tx *gorm.DB) error {
+	p := permissionV7{Name: "security:manage", Description: "Kelola kebijakan keamanan & reset 2FA user"}
+	return tx.Where(permissionV7{Name: p.Name}).FirstOrCreate(&p).Error
+}
+
+func init() {
+	register(Migration{
+		Version: "0009_two_factor",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"TOTPSecret", "TOTPEnabledAt", "TOTPLastStep"} {
+				if err := tx.Migrator().AddColumn(&userV9{}, field); err != nil {
+					return err
+				}
+			}
+			if err := tx.Migrator().CreateTable(&recoveryCodeV9{}, &loginChallengeV9{}, &settingV9{}); err != nil {
+				return err
+			}
+			return seedSecurityPermissionV9(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range []interface{}{&settingV9{}, &loginChallengeV9{}, &recoveryCodeV9{}} {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec("DELETE FROM role_permissions WHERE id_permission IN (SELECT id FROM permissions WHERE name = ?)", "security:manage").Error; err != nil {
+				return err
+			}
+			if err := tx.Where("name = ?", "security:manage").Delete(&permissionV7{}).Error; err != nil {
+				return err
+			}
+			// Lihat 0005: DropColumn GORM di sqlite gagal karena foreign key
+			for _, column := range []string{"totp_secret", "totp_enabled_at", "totp_last_step"} {
+				if err := tx.Exec("ALTER TABLE users DROP COLUMN " + column).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userIdentityV10 struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	IDUser      uint   `gorm:"not null;index"`
+	Provider    string `gorm:"size:32;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject     string `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email       string `gorm:"size:255"`
+	LastLoginAt *time.Time
+	CreatedAt   time.Time
+
+	User *userV1 `gorm:"foreignKey:IDUser"`
+}
+
+func (userIdentityV10) TableName() string { return "user_identities" }
+
+type oidcStateV10 struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	StateHash    string    `gorm:"size:64;not null;unique"`
+	Provider     string    `gorm:"size:32;not null"`
+	Nonce        string    `gorm:"size:64;not null"`
+	CodeVerifier string    `gorm:"size:128;not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	UsedAt       *time.Time
+	CreatedAt    time.Time
+}
+
+func (oidcStateV10) TableName() string { return "oidc_states" }
+
+func init() {
+	register(Migration{
+		Version: "0010_oidc",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&userIdentityV10{}, &oidcStateV10{})
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range []interface{}{&oidcStateV10{}, &userIdentityV10{}} {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
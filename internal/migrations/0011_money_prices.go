@@ -0,0 +1,160 @@
+package migrations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Harga baru disimpan sementara di kolom *_int, lalu menggantikan kolom
+// VARCHAR lama setelah semua baris dikonversi
+type priceColumnsV11 struct {
+	ID               uint
+	HargaReseller    string
+	HargaKonsumen    string
+	HargaResellerInt int64 `gorm:"not null;default:0"`
+	HargaKonsumenInt int64 `gorm:"not null;default:0"`
+}
+
+type produkV11 struct {
+	HargaKonsumen int64 `gorm:"index:idx_produks_harga_konsumen"`
+}
+
+func (produkV11) TableName() string { return "produks" }
+
+type priceRowV11 struct {
+	ID            uint
+	HargaReseller string
+	HargaKonsumen string
+}
+
+// parseLegacyPriceV11 membaca harga lama yang diketik bebas: "150000",
+// "15.000", "Rp 15.000" atau "15.000,00". Titik hanya diterima sebagai
+// pemisah ribuan, koma hanya sebagai pemisah sen (paling banyak dua digit,
+// harus nol). "15,000" bisa berarti lima belas ribu (ribuan gaya Inggris),
+// jadi ditolak; selain itu migrasi dihentikan agar harga tidak berubah
+// diam-diam.
+func parseLegacyPriceV11(s string) (int64, error) {
+	v := strings.TrimSpace(s)
+	v = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(v, "Rp"), "."))
+	v = strings.ReplaceAll(v, " ", "")
+	if whole, frac, ok := strings.Cut(v, ","); ok {
+		if frac == "" || len(frac) > 2 || strings.Trim(frac, "0123456789") != "" {
+			return 0, fmt.Errorf("harga %q ambigu: koma hanya boleh diikuti sen (maks. 2 digit)", s)
+		}
+		if strings.Trim(frac, "0") != "" {
+			return 0, fmt.Errorf("harga %q memiliki sen", s)
+		}
+		v = whole
+	}
+	if groups := strings.Split(v, "."); len(groups) > 1 {
+		for i, g := range groups {
+			if (i > 0 && len(g) != 3) || (i == 0 && (g == "" || len(g) > 3)) {
+				return 0, fmt.Errorf("harga %q bukan format rupiah", s)
+			}
+		}
+		v = strings.Join(groups, "")
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("harga %q bukan format rupiah", s)
+	}
+	return n, nil
+}
+
+// swapColumnsV11 mengganti kolom harga_reseller/harga_konsumen dengan kolom
+// <nama><suffix> yang sudah terisi
+func swapColumnsV11(tx *gorm.DB, table, suffix string) error {
+	for _, column := range []string{"harga_reseller", "harga_konsumen"} {
+		if err := tx.Exec("ALTER TABLE " + table + " DROP COLUMN " + column).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("ALTER TABLE " + table + " RENAME COLUMN " + column + suffix + " TO " + column).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertPricesV11(tx *gorm.DB, table string) error {
+	for _, field := range []string{"HargaResellerInt", "HargaKonsumenInt"} {
+		if err := tx.Table(table).Migrator().AddColumn(&priceColumnsV11{}, field); err != nil {
+			return err
+		}
+	}
+	var rows []priceRowV11
+	if err := tx.Table(table).Select("id, harga_reseller, harga_konsumen").Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		reseller, err := parseLegacyPriceV11(row.HargaReseller)
+		if err != nil {
+			return fmt.Errorf("%s id %d: %w", table, row.ID, err)
+		}
+		konsumen, err := parseLegacyPriceV11(row.HargaKonsumen)
+		if err != nil {
+			return fmt.Errorf("%s id %d: %w", table, row.ID, err)
+		}
+		if err := tx.Table(table).Where("id = ?", row.ID).Updates(map[string]interface{}{
+			"harga_reseller_int": reseller,
+			"harga_konsumen_int": konsumen,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return swapColumnsV11(tx, table, "_int")
+}
+
+func revertPricesV11(tx *gorm.DB, table string) error {
+	for _, column := range []string{"harga_reseller_str", "harga_konsumen_str"} {
+		if err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " VARCHAR(255) NOT NULL DEFAULT ''").Error; err != nil {
+			return err
+		}
+	}
+	var rows []struct {
+		ID            uint
+		HargaReseller int64
+		HargaKonsumen int64
+	}
+	if err := tx.Table(table).Select("id, harga_reseller, harga_konsumen").Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := tx.Table(table).Where("id = ?", row.ID).Updates(map[string]interface{}{
+			"harga_reseller_str": strconv.FormatInt(row.HargaReseller, 10),
+			"harga_konsumen_str": strconv.FormatInt(row.HargaKonsumen, 10),
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return swapColumnsV11(tx, table, "_str")
+}
+
+func init() {
+	register(Migration{
+		Version: "0011_money_prices",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range []string{"produks", "log_produks"} {
+				if err := convertPricesV11(tx, table); err != nil {
+					return err
+				}
+			}
+			// Total Trx/DetailTrx sudah BIGINT (int Go), cukup tipe modelnya
+			// yang berganti ke models.Money
+			return tx.Migrator().CreateIndex(&produkV11{}, "idx_produks_harga_konsumen")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex(&produkV11{}, "idx_produks_harga_konsumen"); err != nil {
+				return err
+			}
+			for _, table := range []string{"log_produks", "produks"} {
+				if err := revertPricesV11(tx, table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
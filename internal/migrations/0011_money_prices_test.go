@@ -0,0 +1,44 @@
+package migrations
+
+import "testing"
+
+func TestParseLegacyPriceV11(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "150000", want: 150000},
+		{in: "15.000", want: 15000},
+		{in: "Rp 15.000", want: 15000},
+		{in: "Rp15.000", want: 15000},
+		{in: "15.000,00", want: 15000},
+		{in: "15.000,0", want: 15000},
+		{in: "1.500.000", want: 1500000},
+		// Koma ribuan gaya Inggris tidak boleh menjadi 15 rupiah
+		{in: "15,000", wantErr: true},
+		{in: "1,500,000", wantErr: true},
+		{in: "15.000,50", wantErr: true},
+		{in: "15,", wantErr: true},
+		{in: "15.00", wantErr: true},
+		{in: "-5000", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "gratis", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseLegacyPriceV11(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseLegacyPriceV11(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseLegacyPriceV11(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseLegacyPriceV11(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type produkV12 struct {
+	IDLogProduk *uint `gorm:"index"`
+}
+
+func (produkV12) TableName() string { return "produks" }
+
+type logProdukV12 struct {
+	IDProduk uint `gorm:"uniqueIndex:idx_log_produks_produk_versi"`
+	Versi    int  `gorm:"not null;default:1;uniqueIndex:idx_log_produks_produk_versi"`
+}
+
+func (logProdukV12) TableName() string { return "log_produks" }
+
+// Kolom produk/snapshot setelah 0011 (harga sudah BIGINT)
+type produkRowV12 struct {
+	ID            uint
+	NamaProduk    string
+	Slug          string
+	HargaReseller int64
+	HargaKonsumen int64
+	Stok          int
+	Deskripsi     string
+	IDToko        uint
+	IDCategory    uint
+}
+
+func (produkRowV12) TableName() string { return "produks" }
+
+type logProdukRowV12 struct {
+	ID            uint
+	IDProduk      uint
+	Versi         int
+	NamaProduk    string
+	Slug          string
+	HargaReseller int64
+	HargaKonsumen int64
+	Deskripsi     string
+	IDToko        uint
+	IDCategory    uint
+	StokAwal      int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (logProdukRowV12) TableName() string { return "log_produks" }
+
+// backfillVersionsV12 menomori snapshot lama per produk sesuai urutan id,
+// menandai yang terakhir sebagai versi terkini, dan membuat snapshot
+// pertama untuk produk yang belum punya
+func backfillVersionsV12(tx *gorm.DB) error {
+	var logs []struct {
+		ID       uint
+		IDProduk uint
+	}
+	if err := tx.Table("log_produks").Select("id, id_produk").Order("id_produk, id").Find(&logs).Error; err != nil {
+		return err
+	}
+	latest := map[uint]uint{}
+	versi := 0
+	for i, l := range logs {
+		if i == 0 || logs[i-1].IDProduk != l.IDProduk {
+			versi = 0
+		}
+		versi++
+		if err := tx.Table("log_produks").Where("id = ?", l.ID).Update("versi", versi).Error; err != nil {
+			return err
+		}
+		latest[l.IDProduk] = l.ID
+	}
+
+	var produks []produkRowV12
+	if err := tx.Find(&produks).Error; err != nil {
+		return err
+	}
+	for _, p := range produks {
+		logID, ok := latest[p.ID]
+		if !ok {
+			now := time.Now()
+			snapshot := logProdukRowV12{
+				IDProduk:      p.ID,
+				Versi:         1,
+				NamaProduk:    p.NamaProduk,
+				Slug:          p.Slug,
+				HargaReseller: p.HargaReseller,
+				HargaKonsumen: p.HargaKonsumen,
+				Deskripsi:     p.Deskripsi,
+				IDToko:        p.IDToko,
+				IDCategory:    p.IDCategory,
+				StokAwal:      p.Stok,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+			if err := tx.Create(&snapshot).Error; err != nil {
+				return err
+			}
+			logID = snapshot.ID
+		}
+		if err := tx.Table("produks").Where("id = ?", p.ID).UpdateColumn("id_log_produk", logID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	register(Migration{
+		Version: "0012_product_versions",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&logProdukV12{}, "Versi"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().AddColumn(&produkV12{}, "IDLogProduk"); err != nil {
+				return err
+			}
+			if err := backfillVersionsV12(tx); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateIndex(&logProdukV12{}, "idx_log_produks_produk_versi"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateIndex(&produkV12{}, "IDLogProduk")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex(&produkV12{}, "IDLogProduk"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropIndex(&logProdukV12{}, "idx_log_produks_produk_versi"); err != nil {
+				return err
+			}
+			if err := tx.Exec("ALTER TABLE produks DROP COLUMN id_log_produk").Error; err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE log_produks DROP COLUMN versi").Error
+		},
+	})
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type produkV13 struct {
+	Status    string         `gorm:"size:16;not null;default:published"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (produkV13) TableName() string { return "produks" }
+
+func init() {
+	register(Migration{
+		Version: "0013_product_status",
+		Up: func(tx *gorm.DB) error {
+			// Produk lama tetap tampil: default status published
+			for _, field := range []string{"Status", "DeletedAt"} {
+				if err := tx.Migrator().AddColumn(&produkV13{}, field); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().CreateIndex(&produkV13{}, "DeletedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			// Produk yang sudah di-soft-delete tidak bisa dihapus permanen (masih
+			// dirujuk log_produks), setelah rollback produk itu tampil lagi
+			if err := tx.Migrator().DropIndex(&produkV13{}, "DeletedAt"); err != nil {
+				return err
+			}
+			for _, column := range []string{"status", "deleted_at"} {
+				if err := tx.Exec("ALTER TABLE produks DROP COLUMN " + column).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type trxV14 struct {
+	ID        uint
+	Status    string `gorm:"size:32;not null;default:pending_payment;index"`
+	CreatedAt time.Time
+}
+
+func (trxV14) TableName() string { return "trxes" }
+
+type orderEventV14 struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	IDTrx      uint   `gorm:"not null;index"`
+	FromStatus string `gorm:"size:32"`
+	ToStatus   string `gorm:"size:32;not null"`
+	IDActor    *uint
+	ActorRole  string `gorm:"size:16;not null"`
+	Note       string `gorm:"size:255"`
+	CreatedAt  time.Time
+
+	Trx *trxV1 `gorm:"foreignKey:IDTrx"`
+}
+
+func (orderEventV14) TableName() string { return "order_events" }
+
+// seedOrderManagePermissionV14 menambah permission order:manage (super-admin
+// sudah mendapatkannya lewat "*")
+func seedOrderManagePermissionV14(tx *gorm.DB) error {
+	p := permissionV7{Name: "order:manage", Description: "Ubah status pesanan semua user (pembayaran, pembatalan, refund)"}
+	return tx.Where(permissionV7{Name: p.Name}).FirstOrCreate(&p).Error
+}
+
+// backfillOrderEventsV14: transaksi lama mulai dari pending_payment dengan
+// satu event awal agar timeline-nya tidak kosong
+func backfillOrderEventsV14(tx *gorm.DB) error {
+	var trxs []trxV14
+	if err := tx.Select("id, created_at").Find(&trxs).Error; err != nil {
+		return err
+	}
+	for _, t := range trxs {
+		event := orderEventV14{
+			IDTrx:     t.ID,
+			ToStatus:  "pending_payment",
+			ActorRole: "system",
+			Note:      "status awal (migrasi)",
+			CreatedAt: t.CreatedAt,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	register(Migration{
+		Version: "0014_order_status",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&trxV14{}, "Status"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateIndex(&trxV14{}, "Status"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateTable(&orderEventV14{}); err != nil {
+				return err
+			}
+			if err := backfillOrderEventsV14(tx); err != nil {
+				return err
+			}
+			return seedOrderManagePermissionV14(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&orderEventV14{}); err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM role_permissions WHERE id_permission IN (SELECT id FROM permissions WHERE name = ?)", "order:manage").Error; err != nil {
+				return err
+			}
+			if err := tx.Where("name = ?", "order:manage").Delete(&permissionV7{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropIndex(&trxV14{}, "Status"); err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE trxes DROP COLUMN status").Error
+		},
+	})
+}
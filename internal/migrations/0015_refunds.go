@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type refundV15 struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	IDTrx       uint   `gorm:"not null;unique"`
+	Amount      int64  `gorm:"not null"`
+	Status      string `gorm:"size:16;not null"`
+	Reason      string `gorm:"size:255"`
+	CompletedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Trx *trxV1 `gorm:"foreignKey:IDTrx"`
+}
+
+func (refundV15) TableName() string { return "refunds" }
+
+func init() {
+	register(Migration{
+		Version: "0015_refunds",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&refundV15{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&refundV15{})
+		},
+	})
+}
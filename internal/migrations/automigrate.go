@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AutoMigrate syncs the tables straight from the current models.
+// Dev-mode only: it never drops or renames columns and leaves no record
+// in schema_migrations, so production databases must use Up instead.
+// The default roles and permissions are seeded afterwards.
+func AutoMigrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Alamat{},
+		&models.Toko{},
+		&models.Category{},
+		&models.Produk{},
+		&models.FotoProduk{},
+		&models.LogProduk{},
+		&models.Trx{},
+		&models.DetailTrx{},
+		&models.OrderEvent{},
+		&models.Refund{},
+		&models.RefreshToken{},
+		&models.Session{},
+		&models.PasswordReset{},
+		&models.VerificationCode{},
+		&models.LoginAttempt{},
+		&models.Permission{},
+		&models.Role{},
+		&models.UserRole{},
+		&models.APIKey{},
+		&models.RecoveryCode{},
+		&models.LoginChallenge{},
+		&models.Setting{},
+		&models.UserIdentity{},
+		&models.OIDCState{},
+	); err != nil {
+		return err
+	}
+	// Role & permission bawaan juga dibutuhkan di dev mode
+	if err := seedRolesV7(db); err != nil {
+		return err
+	}
+	if err := seedSecurityPermissionV9(db); err != nil {
+		return err
+	}
+	return seedOrderManagePermissionV14(db)
+}
@@ -0,0 +1,165 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is one versioned schema change. Versions sort lexically,
+// so they are zero-padded ("0001_initial_schema", "0002_...").
+type Migration struct {
+	Version string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration in the schema_migrations table
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status describes one known migration and whether it has been applied
+type Status struct {
+	Version   string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+var registry []Migration
+
+// register is called from the init() of each migration file
+func register(m Migration) {
+	registry = append(registry, m)
+}
+
+// All returns every registered migration ordered by version
+func All() []Migration {
+	list := make([]Migration, len(registry))
+	copy(list, registry)
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+func ensureTable(db *gorm.DB) error {
+	return db.AutoMigrate(&SchemaMigration{})
+}
+
+// appliedVersions is read-only: a missing schema_migrations table simply
+// means nothing has been applied yet (health checks call this often).
+func appliedVersions(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return map[string]SchemaMigration{}, nil
+	}
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[string]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Up applies every pending migration in order and returns the applied versions
+func Up(db *gorm.DB) ([]string, error) {
+	if err := ensureTable(db); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for _, m := range All() {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s up: %w", m.Version, err)
+		}
+		done = append(done, m.Version)
+	}
+	return done, nil
+}
+
+// Down rolls back the latest `steps` applied migrations, newest first
+func Down(db *gorm.DB, steps int) ([]string, error) {
+	if steps < 1 {
+		return nil, errors.New("steps must be at least 1")
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	all := All()
+	var done []string
+	for i := len(all) - 1; i >= 0 && len(done) < steps; i-- {
+		m := all[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s down: %w", m.Version, err)
+		}
+		done = append(done, m.Version)
+	}
+	return done, nil
+}
+
+// Statuses lists every known migration with its applied state
+func Statuses(db *gorm.DB) ([]Status, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	var list []Status
+	for _, m := range All() {
+		st := Status{Version: m.Version}
+		if row, ok := applied[m.Version]; ok {
+			at := row.AppliedAt
+			st.Applied = true
+			st.AppliedAt = &at
+		}
+		list = append(list, st)
+	}
+	return list, nil
+}
+
+// Pending returns the versions that have not been applied yet
+func Pending(db *gorm.DB) ([]string, error) {
+	list, err := Statuses(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, st := range list {
+		if !st.Applied {
+			pending = append(pending, st.Version)
+		}
+	}
+	return pending, nil
+}
@@ -4,18 +4,21 @@ package models
 
 import "time"
 
-// LogProduk snapshots product data at a specific moment (e.g. creation or checkout)
+// LogProduk snapshots product data at a specific moment. A new version is
+// appended whenever the name, slug, price, description or category changes;
+// Produk.IDLogProduk points at the latest one.
 type LogProduk struct {
 	ID            uint      `gorm:"primaryKey;autoIncrement"`
-	IDProduk      uint      `gorm:"not null"`           // FK → Produk
-	NamaProduk    string    `gorm:"size:255;not null"`  // salin dari produk master
-	Slug          string    `gorm:"size:255;not null"`  // salin dari produk master
-	HargaReseller string    `gorm:"size:255;not null"`  // harga reseller pada saat snapshot
-	HargaKonsumen string    `gorm:"size:255;not null"`  // harga konsumen pada saat snapshot
-	Deskripsi     string    `gorm:"type:text;not null"` // salin dari produk master
-	IDToko        uint      `gorm:"not null"`           // FK → Toko
-	IDCategory    uint      `gorm:"not null"`           // salin dari produk master
-	StokAwal      int       `gorm:"not null"`           // stok pada saat snapshot
+	IDProduk      uint      `gorm:"not null;uniqueIndex:idx_log_produks_produk_versi"`           // FK → Produk
+	Versi         int       `gorm:"not null;default:1;uniqueIndex:idx_log_produks_produk_versi"` // 1, 2, ... per produk
+	NamaProduk    string    `gorm:"size:255;not null"`                                           // salin dari produk master
+	Slug          string    `gorm:"size:255;not null"`                                           // salin dari produk master
+	HargaReseller Money     `gorm:"not null"`                                                    // harga reseller pada saat snapshot
+	HargaKonsumen Money     `gorm:"not null"`                                                    // harga konsumen pada saat snapshot
+	Deskripsi     string    `gorm:"type:text;not null"`                                          // salin dari produk master
+	IDToko        uint      `gorm:"not null"`                                                    // FK → Toko
+	IDCategory    uint      `gorm:"not null"`                                                    // salin dari produk master
+	StokAwal      int       `gorm:"not null"`                                                    // stok pada saat snapshot
 	CreatedAt     time.Time `gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
 
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Scope API key, membatasi apa yang boleh dilakukan integrasi (mis. ERP)
+const (
+	ScopeProductsRead  = "products:read"
+	ScopeProductsWrite = "products:write"
+	ScopeOrdersRead    = "orders:read"
+	ScopeOrdersWrite   = "orders:write"
+)
+
+// APIKeyScopes lists every scope a key can be granted
+var APIKeyScopes = []string{ScopeProductsRead, ScopeProductsWrite, ScopeOrdersRead, ScopeOrdersWrite}
+
+// APIKey is a long-lived credential for server-to-server integrations of a
+// Toko. Only the SHA-256 hash of the key is stored; Prefix is kept in clear
+// so the owner can tell keys apart.
+
+type APIKey struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	IDToko     uint      `gorm:"not null;index"`
+	IDUser     uint      `gorm:"not null"` // pembuat key (pemilik toko)
+	Name       string    `gorm:"size:100;not null"`
+	Prefix     string    `gorm:"size:16;not null"`
+	KeyHash    string    `gorm:"size:64;not null;unique"`
+	Scopes     string    `gorm:"size:255;not null"` // dipisah koma
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastUsedAt *time.Time
+	LastUsedIP string `gorm:"size:64"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	Toko *Toko `gorm:"foreignKey:IDToko"`
+	User *User `gorm:"foreignKey:IDUser"`
+}
+
+// ScopeList returns Scopes as a slice
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// IsActive reports whether the key can still authenticate at now
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
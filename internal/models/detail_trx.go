@@ -5,12 +5,12 @@ import "time"
 // DetailTrx represents transaction detail rows
 
 type DetailTrx struct {
-	ID          uint `gorm:"primaryKey;autoIncrement"`
-	IDTrx       uint `gorm:"not null"`
-	IDLogProduk uint `gorm:"not null"`
-	IDToko      uint `gorm:"not null"`
-	Kuantitas   int  `gorm:"not null"`
-	HargaTotal  int  `gorm:"not null"`
+	ID          uint  `gorm:"primaryKey;autoIncrement"`
+	IDTrx       uint  `gorm:"not null"`
+	IDLogProduk uint  `gorm:"not null"`
+	IDToko      uint  `gorm:"not null"`
+	Kuantitas   int   `gorm:"not null"`
+	HargaTotal  Money `gorm:"not null"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
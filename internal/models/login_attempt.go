@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Hasil percobaan login yang dicatat di LoginAttempt.Reason
+const (
+	LoginOK           = "ok"
+	LoginBadPassword  = "bad_password"
+	LoginUnknownEmail = "unknown_email"
+	LoginLocked       = "locked"
+	LoginBackoff      = "backoff"
+	LoginIPBlocked    = "ip_blocked"
+	LoginBad2FA       = "bad_2fa"
+	LoginBadOIDC      = "bad_oidc" // callback OIDC ditolak (state/id_token tidak valid)
+)
+
+// LoginCredentialFailures are the reasons that count toward the per-IP
+// limit. Refusals (ip_blocked, locked, backoff) are left out, otherwise a
+// blocked IP that keeps retrying would stay blocked forever.
+var LoginCredentialFailures = []string{LoginBadPassword, LoginUnknownEmail, LoginBad2FA, LoginBadOIDC}
+
+// LoginAttempt is the login audit trail. Failed rows per IP also drive
+// the per-IP brute-force limit.
+
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	IDUser    *uint     `gorm:"index"` // kosong bila email tidak terdaftar
+	Email     string    `gorm:"size:255;not null;index"`
+	IPAddress string    `gorm:"size:64;not null;index:idx_login_attempts_ip_created"`
+	UserAgent string    `gorm:"size:255"`
+	Success   bool      `gorm:"not null;default:false"`
+	Reason    string    `gorm:"size:32;not null"`
+	CreatedAt time.Time `gorm:"index:idx_login_attempts_ip_created"`
+
+	User *User `gorm:"foreignKey:IDUser"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Money is an amount in whole rupiah, the smallest unit in use (sen are not
+// used). It is stored as BIGINT so prices can be sorted and filtered in SQL,
+// and serialized as a plain JSON number: "15.000" or 1.5 are rejected when
+// the request is decoded instead of failing later at checkout.
+
+type Money int64
+
+// Mul returns the total for qty units
+func (m Money) Mul(qty int) Money {
+	return m * Money(qty)
+}
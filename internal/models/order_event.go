@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Status pesanan (Trx.Status)
+const (
+	OrderPendingPayment = "pending_payment"
+	OrderPaid           = "paid"
+	OrderProcessing     = "processing"
+	OrderShipped        = "shipped"
+	OrderDelivered      = "delivered"
+	OrderCompleted      = "completed"
+	OrderCancelled      = "cancelled"
+	OrderRefunded       = "refunded"
+)
+
+// orderTransitions is the order state machine: status → allowed next ones
+var orderTransitions = map[string][]string{
+	OrderPendingPayment: {OrderPaid, OrderCancelled},
+	OrderPaid:           {OrderProcessing, OrderCancelled},
+	OrderProcessing:     {OrderShipped, OrderCancelled},
+	OrderShipped:        {OrderDelivered},
+	OrderDelivered:      {OrderCompleted, OrderRefunded}, // retur barang
+	OrderCancelled:      {OrderRefunded},                 // dana pesanan yang sudah dibayar dikembalikan
+}
+
+// NextOrderStatuses returns the statuses an order in status may move to
+func NextOrderStatuses(status string) []string {
+	return orderTransitions[status]
+}
+
+// CanTransition reports whether the state machine allows from → to
+func CanTransition(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Siapa yang melakukan perubahan status
+const (
+	ActorBuyer  = "buyer"
+	ActorSeller = "seller"
+	ActorStaff  = "staff"
+	ActorSystem = "system"
+)
+
+// OrderEvent records one status change of a Trx; ordered by ID they form
+// the order timeline
+
+type OrderEvent struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	IDTrx      uint   `gorm:"not null;index"`
+	FromStatus string `gorm:"size:32"` // kosong untuk event pembuatan pesanan
+	ToStatus   string `gorm:"size:32;not null"`
+	IDActor    *uint  // nil bila dilakukan sistem
+	ActorRole  string `gorm:"size:16;not null"`
+	Note       string `gorm:"size:255"`
+	CreatedAt  time.Time
+
+	Trx *Trx `gorm:"foreignKey:IDTrx"`
+}
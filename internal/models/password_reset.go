@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PasswordReset stores a hashed, single-use password reset token
+
+type PasswordReset struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint       `gorm:"not null;index"`
+	TokenHash string     `gorm:"size:64;not null;unique"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // diisi saat token dipakai atau diganti token baru
+	CreatedAt time.Time
+
+	User *User `gorm:"foreignKey:IDUser"`
+}
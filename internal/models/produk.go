@@ -1,19 +1,33 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status produk: hanya yang published tampil di katalog & bisa dibeli
+const (
+	ProdukPublished = "published"
+	ProdukDraft     = "draft"
+)
 
 type Produk struct {
 	ID            uint   `gorm:"primaryKey;autoIncrement"`
 	NamaProduk    string `gorm:"size:255;not null"`
 	Slug          string `gorm:"size:255;unique;not null"`
-	HargaReseller string `gorm:"size:255;not null"`
-	HargaKonsumen string `gorm:"size:255;not null"`
+	HargaReseller Money  `gorm:"not null"`
+	HargaKonsumen Money  `gorm:"not null;index"`
 	Stok          int    `gorm:"not null"`
 	Deskripsi     string `gorm:"type:text"`
 	IDToko        uint   `gorm:"not null"`
 	IDCategory    uint   `gorm:"not null"`
+	IDLogProduk   *uint  `gorm:"index"` // versi LogProduk terkini, dipakai checkout
+	Status        string `gorm:"size:16;not null;default:published"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	// Soft delete: snapshot & transaksi lama tetap menunjuk produk ini
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 
 	Toko       Toko         `gorm:"foreignKey:IDToko"`
 	Category   Category     `gorm:"foreignKey:IDCategory"`
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RefreshToken stores a hashed, single-use refresh token.
+// Tokens rotated from the same login share a FamilyID, so reuse of an old
+// token can revoke the whole chain.
+
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint       `gorm:"not null;index"`
+	FamilyID  string     `gorm:"size:64;not null;index"`
+	TokenHash string     `gorm:"size:64;not null;unique"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // diisi saat token dirotasi
+	RevokedAt *time.Time // diisi saat logout atau reuse terdeteksi
+	CreatedAt time.Time
+
+	User *User `gorm:"foreignKey:IDUser"`
+}
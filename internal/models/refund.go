@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Status Refund
+const (
+	RefundPending   = "pending"   // pesanan dibayar lalu dibatalkan, dana belum kembali
+	RefundCompleted = "completed" // pesanan sudah berstatus refunded
+)
+
+// Refund is the money owed back to the buyer of a paid order. It is opened
+// when a paid order is cancelled and completed when the order is refunded.
+
+type Refund struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	IDTrx       uint   `gorm:"not null;unique"`
+	Amount      Money  `gorm:"not null"`
+	Status      string `gorm:"size:16;not null"`
+	Reason      string `gorm:"size:255"`
+	CompletedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Trx *Trx `gorm:"foreignKey:IDTrx"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Nama role & permission bawaan. Permission berformat "<resource>:<action>";
+// PermAll memberi semua permission (dipakai super-admin).
+const (
+	RoleSuperAdmin = "super-admin"
+	RoleSupport    = "support"
+
+	PermAll           = "*"
+	PermCategoryRead  = "category:read"
+	PermCategoryWrite = "category:write"
+	PermStoreRead     = "store:read"
+	PermOrderRead     = "order:read"
+	PermOrderManage   = "order:manage"
+	PermSessionRevoke = "session:revoke"
+	PermUserUnlock    = "user:unlock"
+	PermUserAudit     = "user:audit"
+	PermRoleManage    = "role:manage"
+	PermSecurity      = "security:manage"
+)
+
+// Role groups permissions and is assigned to users through user_roles
+// Many-to-many with Permission (role_permissions) and User (user_roles)
+
+type Role struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Name        string `gorm:"size:64;unique;not null"`
+	Description string `gorm:"size:255"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Permissions []*Permission `gorm:"many2many:role_permissions;joinForeignKey:IDRole;joinReferences:IDPermission"`
+}
+
+// Permission is a single action guarded by middleware.RequirePermission
+
+type Permission struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Name        string `gorm:"size:64;unique;not null"`
+	Description string `gorm:"size:255"`
+}
+
+// UserRole links a user to a role
+
+type UserRole struct {
+	IDUser    uint `gorm:"primaryKey"`
+	IDRole    uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+
+	User *User `gorm:"foreignKey:IDUser"`
+	Role *Role `gorm:"foreignKey:IDRole"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Session represents one login on one device.
+// Its ID is carried in the JWT "sid" claim and doubles as the refresh token
+// family ID, so revoking a session invalidates both kinds of token.
+
+type Session struct {
+	ID         string     `gorm:"primaryKey;size:36"`
+	IDUser     uint       `gorm:"not null;index"`
+	UserAgent  string     `gorm:"size:255"`
+	IPAddress  string     `gorm:"size:64"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	LastSeenAt time.Time  `gorm:"not null"`
+	RevokedAt  *time.Time // diisi saat logout, dicabut user, atau admin
+	CreatedAt  time.Time
+
+	User *User `gorm:"foreignKey:IDUser"`
+}
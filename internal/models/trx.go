@@ -9,10 +9,13 @@ type Trx struct {
 	IDUser           uint      `json:"id_user"`
 	AlamatPengiriman uint      `json:"alamat_pengiriman"`
 	MethodBayar      string    `json:"method_bayar"`
-	HargaTotal       int       `json:"harga_total"`
+	Status           string    `gorm:"size:32;not null;default:pending_payment;index" json:"status"`
+	HargaTotal       Money     `json:"harga_total"`
 	KodeInvoice      string    `json:"kode_invoice"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 
-	DetailTrx []DetailTrx `gorm:"foreignKey:IDTrx" json:"detail_trx"`
+	DetailTrx []DetailTrx  `gorm:"foreignKey:IDTrx" json:"detail_trx"`
+	Events    []OrderEvent `gorm:"foreignKey:IDTrx" json:"events,omitempty"`
+	Refund    *Refund      `gorm:"foreignKey:IDTrx" json:"refund,omitempty"`
 }
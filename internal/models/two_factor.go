@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// RecoveryCode is a one-time code that replaces the TOTP code when the
+// authenticator device is lost. Only the SHA-256 hash is stored.
+
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint       `gorm:"not null;index"`
+	CodeHash  string     `gorm:"size:64;not null"`
+	UsedAt    *time.Time // diisi saat kode dipakai login
+	CreatedAt time.Time
+
+	User *User `gorm:"foreignKey:IDUser"`
+}
+
+// Tujuan LoginChallenge
+const (
+	ChallengeTOTP   = "totp"   // user sudah punya 2FA, tinggal masukkan kode
+	ChallengeEnroll = "enroll" // 2FA wajib tapi belum diaktifkan, setup dulu
+)
+
+// LoginChallenge is issued after a correct password when a second factor is
+// still needed. Its token (hashed here) is exchanged for the real JWT.
+
+type LoginChallenge struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint       `gorm:"not null;index"`
+	TokenHash string     `gorm:"size:64;not null;unique"`
+	Purpose   string     `gorm:"size:16;not null"`
+	Attempts  int        `gorm:"not null;default:0"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // diisi saat ditukar dengan token
+	CreatedAt time.Time
+
+	User *User `gorm:"foreignKey:IDUser"`
+}
+
+// Nama Setting yang dikenal
+const (
+	// SettingRequire2FAForAdmins: "true" mewajibkan 2FA untuk user IsAdmin
+	SettingRequire2FAForAdmins = "security.require_2fa_for_admins"
+)
+
+// Setting is a runtime switch changed by admins without a redeploy
+
+type Setting struct {
+	Name      string `gorm:"primaryKey;size:100"`
+	Value     string `gorm:"size:255;not null"`
+	UpdatedAt time.Time
+}
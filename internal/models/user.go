@@ -1,30 +1,67 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
 // User represents the user table
 // One-to-many with Alamat, one-to-one with Toko, one-to-many with Trx
 // Role flag IsAdmin restricts category management
+// EmailVerifiedAt/PhoneVerifiedAt are set once the OTP for that field is confirmed
+// FailedLoginCount/LockedUntil back the login brute-force protection
+// TOTPSecret is set on 2FA setup; 2FA is active once TOTPEnabledAt is set
+// Accounts created by an OIDC login start with a placeholder NoTelp (see HasPhone)
 
 type User struct {
-	ID           uint       `gorm:"primaryKey;autoIncrement"`
-	Nama         string     `gorm:"size:255;not null"`
-	Password     string     `gorm:"size:255;not null"`
-	NoTelp       string     `gorm:"size:255;unique;not null"`
-	Email        string     `gorm:"size:255;unique;not null"`
-	TanggalLahir *time.Time `gorm:"type:date"`
-	JenisKelamin string     `gorm:"size:50"`
-	Tentang      string     `gorm:"type:text"`
-	Pekerjaan    string     `gorm:"size:255"`
-	IDProvinsi   string     `gorm:"size:255"`
-	IDKota       string     `gorm:"size:255"`
-	IsAdmin      bool       `gorm:"default:false"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-
-	Alamat      []*Alamat `gorm:"foreignKey:IDUser"`
-	Toko        *Toko     `gorm:"foreignKey:IDUser"`
-	Trx         []*Trx    `gorm:"foreignKey:IDUser"`
+	ID                uint       `gorm:"primaryKey;autoIncrement"`
+	Nama              string     `gorm:"size:255;not null"`
+	Password          string     `gorm:"size:255;not null" json:"-"`
+	NoTelp            string     `gorm:"size:255;unique;not null"`
+	Email             string     `gorm:"size:255;unique;not null"`
+	TanggalLahir      *time.Time `gorm:"type:date"`
+	JenisKelamin      string     `gorm:"size:50"`
+	Tentang           string     `gorm:"type:text"`
+	Pekerjaan         string     `gorm:"size:255"`
+	IDProvinsi        string     `gorm:"size:255"`
+	IDKota            string     `gorm:"size:255"`
+	IsAdmin           bool       `gorm:"default:false"`
+	EmailVerifiedAt   *time.Time
+	PhoneVerifiedAt   *time.Time
+	FailedLoginCount  int `gorm:"not null;default:0"` // gagal login berturut-turut
+	LastFailedLoginAt *time.Time
+	LockedUntil       *time.Time // akun terkunci sampai waktu ini
+	TOTPSecret        string     `gorm:"size:64" json:"-"`
+	TOTPEnabledAt     *time.Time
+	TOTPLastStep      int64 `gorm:"not null;default:0" json:"-"` // periode TOTP terakhir yang dipakai (anti replay)
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+
+	Alamat []*Alamat `gorm:"foreignKey:IDUser"`
+	Toko   *Toko     `gorm:"foreignKey:IDUser"`
+	Trx    []*Trx    `gorm:"foreignKey:IDUser"`
+}
+
+// PhonePlaceholderPrefix marks the NoTelp of an account created through an
+// OIDC login that has no phone number yet; no_telp is unique and not null
+const PhonePlaceholderPrefix = "oidc:"
+
+// HasPhone reports whether the user has entered a real phone number
+func (u *User) HasPhone() bool {
+	return !strings.HasPrefix(u.NoTelp, PhonePlaceholderPrefix)
+}
+
+// IsVerified reports whether both email and phone number are verified
+func (u *User) IsVerified() bool {
+	return u.EmailVerifiedAt != nil && u.PhoneVerifiedAt != nil
+}
+
+// HasTwoFactor reports whether TOTP 2FA is enabled
+func (u *User) HasTwoFactor() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// IsLocked reports whether the account is temporarily locked at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// UserIdentity links an external OpenID Connect account (provider + sub)
+// to a User. One user can have identities at several providers.
+
+type UserIdentity struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	IDUser      uint   `gorm:"not null;index"`
+	Provider    string `gorm:"size:32;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject     string `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email       string `gorm:"size:255"` // email dari provider saat terakhir login
+	LastLoginAt *time.Time
+	CreatedAt   time.Time
+
+	User *User `gorm:"foreignKey:IDUser"`
+}
+
+// OIDCState remembers a login that was sent to a provider until the browser
+// comes back to the callback. Only the hash of the state parameter is stored.
+
+type OIDCState struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement"`
+	StateHash    string     `gorm:"size:64;not null;unique"`
+	Provider     string     `gorm:"size:32;not null"`
+	Nonce        string     `gorm:"size:64;not null"`
+	CodeVerifier string     `gorm:"size:128;not null"` // PKCE
+	ExpiresAt    time.Time  `gorm:"not null"`
+	UsedAt       *time.Time // diisi saat callback diterima
+	CreatedAt    time.Time
+}
+
+// TableName: tanpa ini GORM menamai tabelnya o_id_c_states
+func (OIDCState) TableName() string { return "oidc_states" }
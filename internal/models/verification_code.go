@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Channel verifikasi akun
+const (
+	VerificationEmail = "email"
+	VerificationPhone = "phone"
+)
+
+// VerificationCode stores a hashed one-time code sent to the user's email or
+// phone. Target keeps the address the code was sent to, so a code cannot
+// verify a value that changed afterwards.
+
+type VerificationCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint       `gorm:"not null;index"`
+	Channel   string     `gorm:"size:16;not null"`
+	Target    string     `gorm:"size:255;not null"`
+	CodeHash  string     `gorm:"size:64;not null"`
+	Attempts  int        `gorm:"not null;default:0"` // jumlah percobaan kode salah
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // diisi saat kode dipakai atau diganti kode baru
+	CreatedAt time.Time
+
+	User *User `gorm:"foreignKey:IDUser"`
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"FinalTask/config"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email. Services depend on this interface only, so the
+// transport can be swapped through MAIL_DRIVER without touching them.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer returns the sender selected by cfg.Driver
+func NewMailer(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "log":
+		return &logMailer{from: cfg.From}, nil
+	case "outbox":
+		if err := os.MkdirAll(cfg.OutboxDir, 0o755); err != nil {
+			return nil, fmt.Errorf("create outbox dir: %w", err)
+		}
+		return &outboxMailer{from: cfg.From, dir: cfg.OutboxDir}, nil
+	case "smtp":
+		return &smtpMailer{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("mail driver %q tidak dikenal", cfg.Driver)
+	}
+}
+
+// logMailer hanya mencetak email ke log, cocok untuk development
+type logMailer struct {
+	from string
+}
+
+func (m *logMailer) Send(_ context.Context, msg Message) error {
+	log.Printf("📧 [mail] from=%s to=%s subject=%q\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// outboxMailer menulis setiap email sebagai file .eml di folder outbox,
+// sehingga alur reset password bisa dites tanpa server SMTP
+type outboxMailer struct {
+	from string
+	dir  string
+}
+
+func (m *outboxMailer) Send(_ context.Context, msg Message) error {
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(m.dir, name), compose(m.from, msg), 0o600)
+}
+
+type smtpMailer struct {
+	cfg config.MailConfig
+}
+
+func (m *smtpMailer) Send(_ context.Context, msg Message) error {
+	addr := m.cfg.SMTPHost + ":" + m.cfg.SMTPPort
+	var auth smtp.Auth
+	if m.cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", m.cfg.SMTPUser, m.cfg.SMTPPass, m.cfg.SMTPHost)
+	}
+	return smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, compose(m.cfg.From, msg))
+}
+
+func compose(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
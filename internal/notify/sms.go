@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"FinalTask/config"
+)
+
+// SMS is a text message to a phone number
+type SMS struct {
+	To   string
+	Body string
+}
+
+// SMSSender delivers SMS; the gateway is chosen through SMS_DRIVER
+type SMSSender interface {
+	Send(ctx context.Context, msg SMS) error
+}
+
+// NewSMSSender returns the sender selected by cfg.Driver
+func NewSMSSender(cfg config.SMSConfig) (SMSSender, error) {
+	switch cfg.Driver {
+	case "log":
+		return &logSMS{}, nil
+	case "outbox":
+		if err := os.MkdirAll(cfg.OutboxDir, 0o755); err != nil {
+			return nil, fmt.Errorf("create sms outbox dir: %w", err)
+		}
+		return &outboxSMS{dir: cfg.OutboxDir}, nil
+	case "webhook":
+		return &webhookSMS{url: cfg.WebhookURL, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("sms driver %q tidak dikenal", cfg.Driver)
+	}
+}
+
+type logSMS struct{}
+
+func (s *logSMS) Send(_ context.Context, msg SMS) error {
+	log.Printf("📱 [sms] to=%s\n%s", msg.To, msg.Body)
+	return nil
+}
+
+// outboxSMS menulis setiap SMS sebagai file .sms, pengganti gateway saat lokal
+type outboxSMS struct {
+	dir string
+}
+
+func (s *outboxSMS) Send(_ context.Context, msg SMS) error {
+	name := fmt.Sprintf("%s_%s.sms", time.Now().Format("20060102T150405.000000000"), sanitize(msg.To))
+	body := fmt.Sprintf("To: %s\n\n%s\n", msg.To, msg.Body)
+	return os.WriteFile(filepath.Join(s.dir, name), []byte(body), 0o600)
+}
+
+// webhookSMS mengirim {"to","body"} sebagai JSON ke gateway SMS
+type webhookSMS struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSMS) Send(ctx context.Context, msg SMS) error {
+	payload, err := json.Marshal(map[string]string{"to": msg.To, "body": msg.Body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway membalas %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// jwk is a public key from the provider's JWKS (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err1 := decodeBigInt(k.N)
+		e, err2 := decodeBigInt(k.E)
+		if err1 != nil || err2 != nil || !e.IsInt64() {
+			return nil, errors.New("kunci RSA tidak valid")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("kurva %s tidak didukung", k.Crv)
+		}
+		x, err1 := decodeBigInt(k.X)
+		y, err2 := decodeBigInt(k.Y)
+		if err1 != nil || err2 != nil || !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("kunci EC tidak valid")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("kunci Ed25519 tidak valid")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("tipe kunci %q tidak didukung", k.Kty)
+	}
+}
+
+func decodeBigInt(v string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("nilai base64url tidak valid")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
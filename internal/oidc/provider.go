@@ -0,0 +1,352 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"FinalTask/config"
+	"FinalTask/internal/apperr"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Algoritma id_token yang diterima; "none" dan HMAC tidak pernah diterima
+var idTokenAlgs = []string{"RS256", "ES256", "EdDSA"}
+
+// Jeda minimum sebelum JWKS diambil ulang karena kid tidak dikenal
+const jwksRefreshInterval = time.Minute
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+var (
+	// ErrUpstream wraps every failure talking to the identity provider
+	ErrUpstream       = apperr.Upstream("gagal berkomunikasi dengan penyedia identitas")
+	ErrInvalidIDToken = apperr.Unauthorized("id_token dari penyedia identitas tidak valid")
+)
+
+// Claims is the identity the provider vouches for in the id_token
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider talks to one OpenID Connect identity provider using the
+// authorization code flow with PKCE
+type Provider struct {
+	cfg    config.OIDCProviderConfig
+	client *http.Client
+
+	mu     sync.Mutex
+	meta   *metadata
+	keys   map[string]crypto.PublicKey
+	keysAt time.Time
+}
+
+// metadata is the part of the discovery document we need
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Registry holds the configured providers in configuration order
+type Registry struct {
+	providers map[string]*Provider
+	order     []string
+}
+
+// NewRegistry builds the providers from config. Nothing is fetched until
+// the first login, so an unreachable provider does not block startup.
+func NewRegistry(cfgs []config.OIDCProviderConfig) *Registry {
+	r := &Registry{providers: map[string]*Provider{}}
+	client := &http.Client{Timeout: 10 * time.Second}
+	for _, cfg := range cfgs {
+		if len(cfg.Scopes) == 0 {
+			cfg.Scopes = defaultScopes
+		}
+		if cfg.DisplayName == "" {
+			cfg.DisplayName = cfg.Name
+		}
+		r.providers[cfg.Name] = &Provider{cfg: cfg, client: client}
+		r.order = append(r.order, cfg.Name)
+	}
+	return r
+}
+
+// Get returns the provider with the given name
+func (r *Registry) Get(name string) (*Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// List returns every provider in configuration order
+func (r *Registry) List() []*Provider {
+	list := make([]*Provider, 0, len(r.order))
+	for _, name := range r.order {
+		list = append(list, r.providers[name])
+	}
+	return list
+}
+
+func (p *Provider) Name() string        { return p.cfg.Name }
+func (p *Provider) DisplayName() string { return p.cfg.DisplayName }
+
+// PKCEChallenge returns the S256 code challenge of a verifier (RFC 7636)
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL is where the browser is sent to log in at the provider
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	scopes := p.cfg.Scopes
+	if !contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange menukar authorization code dengan token, lalu memverifikasi
+// id_token (tanda tangan, iss, aud, exp dan nonce)
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic: id & secret di-URL-encode dulu (RFC 6749 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(req, &body)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || body.IDToken == "" {
+		if body.Error != "" {
+			return nil, fmt.Errorf("%w: token endpoint: %s %s", ErrUpstream, body.Error, body.ErrorDescription)
+		}
+		return nil, fmt.Errorf("%w: token endpoint membalas %d tanpa id_token", ErrUpstream, status)
+	}
+	return p.verify(ctx, meta, body.IDToken, nonce)
+}
+
+func (p *Provider) verify(ctx context.Context, meta *metadata, raw, nonce string) (*Claims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods(idTokenAlgs))
+	token, err := parser.Parse(raw, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	})
+	if err != nil {
+		if errors.Is(err, ErrUpstream) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidIDToken
+	}
+	if !claims.VerifyIssuer(meta.Issuer, true) {
+		return nil, fmt.Errorf("%w: issuer", ErrInvalidIDToken)
+	}
+	if !claims.VerifyAudience(p.cfg.ClientID, true) {
+		return nil, fmt.Errorf("%w: audience", ErrInvalidIDToken)
+	}
+	// Bila aud berisi beberapa client, azp wajib client kita (OIDC Core 3.1.3.7)
+	if aud, isList := claims["aud"].([]interface{}); isList && len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.cfg.ClientID {
+			return nil, fmt.Errorf("%w: azp", ErrInvalidIDToken)
+		}
+	}
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return nil, fmt.Errorf("%w: nonce", ErrInvalidIDToken)
+	}
+	if _, hasExp := claims["exp"]; !hasExp {
+		return nil, fmt.Errorf("%w: exp", ErrInvalidIDToken)
+	}
+
+	c := &Claims{}
+	c.Subject, _ = claims["sub"].(string)
+	c.Email, _ = claims["email"].(string)
+	c.Name, _ = claims["name"].(string)
+	// Beberapa provider mengirim email_verified sebagai string "true"
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		c.EmailVerified = v
+	case string:
+		c.EmailVerified = v == "true"
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: sub kosong", ErrInvalidIDToken)
+	}
+	return c, nil
+}
+
+// discover mengambil discovery document sekali lalu menyimpannya
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta metadata
+	status, err := p.doJSON(req, &meta)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: discovery %s membalas %d", ErrUpstream, wellKnown, status)
+	}
+	// Issuer di dokumen harus sama persis dengan yang dikonfigurasi
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("%w: issuer discovery %q tidak sama dengan konfigurasi %q", ErrUpstream, meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: discovery %s tidak lengkap", ErrUpstream, wellKnown)
+	}
+	p.meta = &meta
+	return p.meta, nil
+}
+
+// key mencari kunci publik untuk kid; JWKS diambil ulang bila kid belum
+// dikenal (rotasi kunci di provider), paling sering sekali per menit
+func (p *Provider) key(ctx context.Context, meta *metadata, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+	keys, err := p.fetchJWKS(ctx, meta.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys, p.keysAt = keys, time.Now()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+}
+
+// lookupKey: token tanpa kid hanya diterima bila JWKS berisi satu kunci
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) fetchJWKS(ctx context.Context, uri string) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	status, err := p.doJSON(req, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: jwks membalas %d", ErrUpstream, status)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue // tipe kunci yang tidak didukung dilewati saja
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, dst interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("%w: respons bukan JSON: %v", ErrUpstream, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,10 @@
 package repository
 
 import (
-	"FinalTask/config"
 	"FinalTask/internal/models"
 	"context"
+
+	"gorm.io/gorm"
 )
 
 type AddressRepository interface {
@@ -14,19 +15,21 @@ type AddressRepository interface {
 	Delete(ctx context.Context, id uint) error
 }
 
-type addressRepo struct{}
+type addressRepo struct {
+	db *gorm.DB
+}
 
-func NewAddressRepository() AddressRepository {
-	return &addressRepo{}
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepo{db: db}
 }
 
 func (r *addressRepo) Create(ctx context.Context, addr *models.Alamat) error {
-	return config.DB.WithContext(ctx).Create(addr).Error
+	return r.db.WithContext(ctx).Create(addr).Error
 }
 
 func (r *addressRepo) ListByUserID(ctx context.Context, userID uint) ([]*models.Alamat, error) {
 	var list []*models.Alamat
-	err := config.DB.WithContext(ctx).
+	err := r.db.WithContext(ctx).
 		Where("id_user = ?", userID).
 		Find(&list).Error
 	return list, err
@@ -34,14 +37,14 @@ func (r *addressRepo) ListByUserID(ctx context.Context, userID uint) ([]*models.
 
 func (r *addressRepo) FindByID(ctx context.Context, id uint) (*models.Alamat, error) {
 	var addr models.Alamat
-	err := config.DB.WithContext(ctx).First(&addr, id).Error
+	err := r.db.WithContext(ctx).First(&addr, id).Error
 	return &addr, err
 }
 
 func (r *addressRepo) Update(ctx context.Context, addr *models.Alamat) error {
-	return config.DB.WithContext(ctx).Save(addr).Error
+	return r.db.WithContext(ctx).Save(addr).Error
 }
 
 func (r *addressRepo) Delete(ctx context.Context, id uint) error {
-	return config.DB.WithContext(ctx).Delete(&models.Alamat{}, id).Error
+	return r.db.WithContext(ctx).Delete(&models.Alamat{}, id).Error
 }
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	ListByStoreID(ctx context.Context, storeID uint) ([]*models.APIKey, error)
+	FindByID(ctx context.Context, storeID, id uint) (*models.APIKey, error)
+	FindByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	// CountActive counts keys of a store that are neither revoked nor expired
+	CountActive(ctx context.Context, storeID uint, now time.Time) (int64, error)
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	// TouchLastUsed records usage, at most once per interval so busy
+	// integrations do not turn every request into a write
+	TouchLastUsed(ctx context.Context, id uint, at time.Time, ip string, interval time.Duration) error
+}
+
+type apiKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *apiKeyRepo) ListByStoreID(ctx context.Context, storeID uint) ([]*models.APIKey, error) {
+	var list []*models.APIKey
+	err := r.db.WithContext(ctx).
+		Where("id_toko = ?", storeID).
+		Order("id DESC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *apiKeyRepo) FindByID(ctx context.Context, storeID, id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).
+		Where("id = ? AND id_toko = ?", id, storeID).
+		First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepo) FindByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).
+		Where("key_hash = ?", hash).
+		First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepo) CountActive(ctx context.Context, storeID uint, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id_toko = ? AND revoked_at IS NULL AND expires_at > ?", storeID, now).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id uint, at time.Time, ip string, interval time.Duration) error {
+	return r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-interval)).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
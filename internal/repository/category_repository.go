@@ -1,9 +1,10 @@
 package repository
 
 import (
-	"FinalTask/config"
 	"FinalTask/internal/models"
 	"context"
+
+	"gorm.io/gorm"
 )
 
 type CategoryRepository interface {
@@ -14,32 +15,34 @@ type CategoryRepository interface {
 	Delete(ctx context.Context, id uint) error
 }
 
-type categoryRepo struct{}
+type categoryRepo struct {
+	db *gorm.DB
+}
 
-func NewCategoryRepository() CategoryRepository {
-	return &categoryRepo{}
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepo{db: db}
 }
 
 func (r *categoryRepo) Create(ctx context.Context, cat *models.Category) error {
-	return config.DB.WithContext(ctx).Create(cat).Error
+	return r.db.WithContext(ctx).Create(cat).Error
 }
 
 func (r *categoryRepo) List(ctx context.Context) ([]*models.Category, error) {
 	var list []*models.Category
-	err := config.DB.WithContext(ctx).Find(&list).Error
+	err := r.db.WithContext(ctx).Find(&list).Error
 	return list, err
 }
 
 func (r *categoryRepo) FindByID(ctx context.Context, id uint) (*models.Category, error) {
 	var cat models.Category
-	err := config.DB.WithContext(ctx).First(&cat, id).Error
+	err := r.db.WithContext(ctx).First(&cat, id).Error
 	return &cat, err
 }
 
 func (r *categoryRepo) Update(ctx context.Context, cat *models.Category) error {
-	return config.DB.WithContext(ctx).Save(cat).Error
+	return r.db.WithContext(ctx).Save(cat).Error
 }
 
 func (r *categoryRepo) Delete(ctx context.Context, id uint) error {
-	return config.DB.WithContext(ctx).Delete(&models.Category{}, id).Error
+	return r.db.WithContext(ctx).Delete(&models.Category{}, id).Error
 }
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *models.LoginAttempt) error
+	// CountFailuresByIP counts credential failures from ip since the given time
+	CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int64, error)
+	// OldestFailureByIP returns the first credential failure from ip since the given time
+	OldestFailureByIP(ctx context.Context, ip string, since time.Time) (*models.LoginAttempt, error)
+	ListByUserID(ctx context.Context, userID uint, limit int) ([]*models.LoginAttempt, error)
+}
+
+type loginAttemptRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepo{db: db}
+}
+
+func (r *loginAttemptRepo) Create(ctx context.Context, attempt *models.LoginAttempt) error {
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
+
+func (r *loginAttemptRepo) CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Model(&models.LoginAttempt{}).
+		Where("ip_address = ? AND success = ? AND reason IN ? AND created_at >= ?", ip, false, models.LoginCredentialFailures, since).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *loginAttemptRepo) OldestFailureByIP(ctx context.Context, ip string, since time.Time) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	if err := r.db.WithContext(ctx).
+		Where("ip_address = ? AND success = ? AND reason IN ? AND created_at >= ?", ip, false, models.LoginCredentialFailures, since).
+		Order("created_at ASC").
+		First(&attempt).Error; err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepo) ListByUserID(ctx context.Context, userID uint, limit int) ([]*models.LoginAttempt, error) {
+	var list []*models.LoginAttempt
+	err := r.db.WithContext(ctx).
+		Where("id_user = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
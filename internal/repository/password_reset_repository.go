@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, reset *models.PasswordReset) error
+	FindByHash(ctx context.Context, hash string) (*models.PasswordReset, error)
+	// MarkUsed consumes an unused token. It returns false when the token
+	// was already used, so a reset link works only once.
+	MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error)
+	// InvalidateByUserID consumes every pending token of the user
+	InvalidateByUserID(ctx context.Context, userID uint, at time.Time) error
+}
+
+type passwordResetRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepo{db: db}
+}
+
+func (r *passwordResetRepo) Create(ctx context.Context, reset *models.PasswordReset) error {
+	return r.db.WithContext(ctx).Create(reset).Error
+}
+
+func (r *passwordResetRepo) FindByHash(ctx context.Context, hash string) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	if err := r.db.WithContext(ctx).
+		Where("token_hash = ?", hash).
+		First(&reset).Error; err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+func (r *passwordResetRepo) MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *passwordResetRepo) InvalidateByUserID(ctx context.Context, userID uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PasswordReset{}).
+		Where("id_user = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
@@ -3,15 +3,24 @@ package repository
 import (
 	"context"
 
-	"FinalTask/config"
 	"FinalTask/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ProductFilter narrows GET /products; zero values mean "no filter"
+type ProductFilter struct {
+	Status     string // mis. models.ProdukPublished untuk katalog publik
+	CategoryID uint
+	MinHarga   models.Money // harga konsumen minimum
+	MaxHarga   models.Money // harga konsumen maksimum
+	Sort       string       // "harga" / "-harga", default urut id
+}
+
 type ProductRepository interface {
 	Create(ctx context.Context, prod *models.Produk) error
-	List(ctx context.Context, offset, limit int, categoryID uint) ([]*models.Produk, error)
+	List(ctx context.Context, offset, limit int, filter ProductFilter) ([]*models.Produk, error)
 	FindByID(ctx context.Context, id uint) (*models.Produk, error)
 	Update(ctx context.Context, prod *models.Produk) error
 	Delete(ctx context.Context, id uint) error
@@ -21,79 +30,162 @@ type ProductRepository interface {
 	// **Tambah** CreateLog:
 	CreateLog(ctx context.Context, log *models.LogProduk) error
 
+	// Riwayat versi: LatestLogVersion = 0 bila produk belum punya snapshot
+	LatestLogVersion(ctx context.Context, produkID uint) (int, error)
+	ListLogs(ctx context.Context, produkID uint) ([]*models.LogProduk, error)
+	SetCurrentLog(ctx context.Context, produkID, logID uint) error
+
 	// untuk transaksi
 	FindLogByID(ctx context.Context, logID uint) (*models.LogProduk, error)
-	UpdateStock(ctx context.Context, produkID uint, qty int) error
+	// FindForCheckout also returns soft-deleted products so checkout can say why
+	FindForCheckout(ctx context.Context, id uint) (*models.Produk, error)
+	// DecrementStock returns false (no change) when fewer than qty are left
+	DecrementStock(ctx context.Context, produkID uint, qty int) (bool, error)
+	// RestoreStock puts qty units back (pembatalan pesanan)
+	RestoreStock(ctx context.Context, produkID uint, qty int) error
 }
 
-type productRepo struct{}
+type productRepo struct {
+	db *gorm.DB
+}
 
-func NewProductRepository() ProductRepository {
-	return &productRepo{}
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &productRepo{db: db}
 }
 
 func (r *productRepo) Create(ctx context.Context, prod *models.Produk) error {
-	return config.DB.WithContext(ctx).Create(prod).Error
+	return r.db.WithContext(ctx).Create(prod).Error
 }
 
 func (r *productRepo) CreateLog(ctx context.Context, log *models.LogProduk) error {
-	return config.DB.WithContext(ctx).Create(log).Error
+	return r.db.WithContext(ctx).Create(log).Error
 }
 
-func (r *productRepo) List(ctx context.Context, offset, limit int, categoryID uint) ([]*models.Produk, error) {
+func (r *productRepo) List(ctx context.Context, offset, limit int, filter ProductFilter) ([]*models.Produk, error) {
 	var list []*models.Produk
-	db := config.DB.WithContext(ctx)
-	if categoryID != 0 {
-		db = db.Where("id_category = ?", categoryID)
+	db := r.db.WithContext(ctx)
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.CategoryID != 0 {
+		db = db.Where("id_category = ?", filter.CategoryID)
+	}
+	if filter.MinHarga > 0 {
+		db = db.Where("harga_konsumen >= ?", filter.MinHarga)
+	}
+	if filter.MaxHarga > 0 {
+		db = db.Where("harga_konsumen <= ?", filter.MaxHarga)
+	}
+	switch filter.Sort {
+	case "harga":
+		db = db.Order("harga_konsumen ASC, id ASC")
+	case "-harga":
+		db = db.Order("harga_konsumen DESC, id ASC")
+	default:
+		db = db.Order("id ASC")
 	}
 	err := db.Offset(offset).
 		Limit(limit).
 		Preload("FotoProduk").
 		Preload("Category").
 		Preload("Toko").
-		Preload("Toko.User").
 		Find(&list).Error
 	return list, err
 }
 
 func (r *productRepo) FindByID(ctx context.Context, id uint) (*models.Produk, error) {
 	var prod models.Produk
-	err := config.DB.WithContext(ctx).
+	err := r.db.WithContext(ctx).
 		Preload("FotoProduk", func(db *gorm.DB) *gorm.DB {
 			return db.Omit("Produk")
 		}).
 		Preload("Category").
 		Preload("Toko").
-		Preload("Toko.User").
 		First(&prod, id).Error
 	return &prod, err
 }
 
+// Update saves the product columns only; the preloaded Category/Toko would
+// otherwise be upserted and overwrite a changed id_category
 func (r *productRepo) Update(ctx context.Context, prod *models.Produk) error {
-	return config.DB.WithContext(ctx).Save(prod).Error
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(prod).Error
 }
 
 func (r *productRepo) Delete(ctx context.Context, id uint) error {
-	return config.DB.WithContext(ctx).Delete(&models.Produk{}, id).Error
+	return r.db.WithContext(ctx).Delete(&models.Produk{}, id).Error
 }
 
 func (r *productRepo) CreatePhoto(ctx context.Context, photo *models.FotoProduk) error {
-	return config.DB.WithContext(ctx).Create(photo).Error
+	return r.db.WithContext(ctx).Create(photo).Error
 }
 
 // FindLogByID retrieves a LogProduk entry by its ID
 func (r *productRepo) FindLogByID(ctx context.Context, logID uint) (*models.LogProduk, error) {
 	var logEntry models.LogProduk
-	if err := config.DB.WithContext(ctx).First(&logEntry, logID).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&logEntry, logID).Error; err != nil {
 		return nil, err
 	}
 	return &logEntry, nil
 }
 
-// UpdateStock decreases the 'stok' field of a Produk by the given qty
-func (r *productRepo) UpdateStock(ctx context.Context, produkID uint, qty int) error {
-	return config.DB.WithContext(ctx).
+// LatestLogVersion returns the highest Versi recorded for a product
+func (r *productRepo) LatestLogVersion(ctx context.Context, produkID uint) (int, error) {
+	var versi int
+	err := r.db.WithContext(ctx).
+		Model(&models.LogProduk{}).
+		Where("id_produk = ?", produkID).
+		Select("COALESCE(MAX(versi), 0)").
+		Scan(&versi).Error
+	return versi, err
+}
+
+// ListLogs returns every snapshot of a product, newest version first
+func (r *productRepo) ListLogs(ctx context.Context, produkID uint) ([]*models.LogProduk, error) {
+	var list []*models.LogProduk
+	err := r.db.WithContext(ctx).
+		Where("id_produk = ?", produkID).
+		Order("versi DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// SetCurrentLog marks logID as the version checkout binds to. UpdateColumn
+// leaves updated_at alone; the product row was just saved anyway.
+func (r *productRepo) SetCurrentLog(ctx context.Context, produkID, logID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Produk{}).
+		Where("id = ?", produkID).
+		UpdateColumn("id_log_produk", logID).Error
+}
+
+// FindForCheckout loads the bare product row, including soft-deleted ones
+func (r *productRepo) FindForCheckout(ctx context.Context, id uint) (*models.Produk, error) {
+	var prod models.Produk
+	if err := r.db.WithContext(ctx).Unscoped().First(&prod, id).Error; err != nil {
+		return nil, err
+	}
+	return &prod, nil
+}
+
+// DecrementStock takes qty units only if at least qty are left. The check
+// and the update are a single statement, so concurrent checkouts cannot both
+// pass it and stok never goes negative; the row stays locked until the
+// surrounding transaction ends. Plain SQL, runs unchanged on MySQL,
+// PostgreSQL and SQLite. Soft-deleted products are never matched.
+func (r *productRepo) DecrementStock(ctx context.Context, produkID uint, qty int) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.Produk{}).
+		Where("id = ? AND stok >= ?", produkID, qty).
+		UpdateColumn("stok", gorm.Expr("stok - ?", qty))
+	return res.RowsAffected == 1, res.Error
+}
+
+// RestoreStock adds qty units back to a product, soft-deleted ones included
+// so a cancelled order always returns what it took
+func (r *productRepo) RestoreStock(ctx context.Context, produkID uint, qty int) error {
+	return r.db.WithContext(ctx).
+		Unscoped().
 		Model(&models.Produk{}).
 		Where("id = ?", produkID).
-		UpdateColumn("stok", gorm.Expr("stok - ?", qty)).Error
+		UpdateColumn("stok", gorm.Expr("stok + ?", qty)).Error
 }
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	// MarkUsed flags an active token as rotated. It returns false when the
+	// token was already used or revoked, which signals reuse.
+	MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	RevokeByUserID(ctx context.Context, userID uint, at time.Time) error
+}
+
+type refreshTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepo{db: db}
+}
+
+func (r *refreshTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *refreshTokenRepo) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.WithContext(ctx).
+		Where("token_hash = ?", hash).
+		First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed is a conditional update so two concurrent refreshes with the same
+// token cannot both succeed
+func (r *refreshTokenRepo) MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+func (r *refreshTokenRepo) RevokeByUserID(ctx context.Context, userID uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("id_user = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
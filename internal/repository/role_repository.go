@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository interface {
+	List(ctx context.Context) ([]*models.Role, error)
+	FindByID(ctx context.Context, id uint) (*models.Role, error)
+	FindByName(ctx context.Context, name string) (*models.Role, error)
+	Create(ctx context.Context, role *models.Role) error
+	Update(ctx context.Context, role *models.Role) error
+	ReplacePermissions(ctx context.Context, role *models.Role, perms []*models.Permission) error
+	// Delete removes the role together with its permission links and assignments
+	Delete(ctx context.Context, id uint) error
+
+	ListPermissions(ctx context.Context) ([]*models.Permission, error)
+	FindPermissionsByNames(ctx context.Context, names []string) ([]*models.Permission, error)
+
+	// ListByUserID returns the roles of a user with their permissions
+	ListByUserID(ctx context.Context, userID uint) ([]*models.Role, error)
+	Assign(ctx context.Context, userID, roleID uint, at time.Time) error
+	Unassign(ctx context.Context, userID, roleID uint) error
+	CountUsers(ctx context.Context, roleID uint) (int64, error)
+}
+
+type roleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepo{db: db}
+}
+
+func (r *roleRepo) List(ctx context.Context) ([]*models.Role, error) {
+	var list []*models.Role
+	err := r.db.WithContext(ctx).
+		Preload("Permissions").
+		Order("id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *roleRepo) FindByID(ctx context.Context, id uint) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.WithContext(ctx).
+		Preload("Permissions").
+		First(&role, id).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepo) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.WithContext(ctx).
+		Preload("Permissions").
+		Where("name = ?", name).
+		First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepo) Create(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+func (r *roleRepo) Update(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Omit("Permissions").Save(role).Error
+}
+
+func (r *roleRepo) ReplacePermissions(ctx context.Context, role *models.Role, perms []*models.Permission) error {
+	return r.db.WithContext(ctx).Model(role).Association("Permissions").Replace(perms)
+}
+
+func (r *roleRepo) Delete(ctx context.Context, id uint) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("id_role = ?", id).Delete(&models.UserRole{}).Error; err != nil {
+		return err
+	}
+	return db.Select("Permissions").Delete(&models.Role{ID: id}).Error
+}
+
+func (r *roleRepo) ListPermissions(ctx context.Context) ([]*models.Permission, error) {
+	var list []*models.Permission
+	err := r.db.WithContext(ctx).Order("name ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *roleRepo) FindPermissionsByNames(ctx context.Context, names []string) ([]*models.Permission, error) {
+	var list []*models.Permission
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&list).Error
+	return list, err
+}
+
+func (r *roleRepo) ListByUserID(ctx context.Context, userID uint) ([]*models.Role, error) {
+	var list []*models.Role
+	err := r.db.WithContext(ctx).
+		Joins("JOIN user_roles ON user_roles.id_role = roles.id").
+		Where("user_roles.id_user = ?", userID).
+		Preload("Permissions").
+		Order("roles.id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// Assign is idempotent: assigning a role twice is not an error
+func (r *roleRepo) Assign(ctx context.Context, userID, roleID uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserRole{IDUser: userID, IDRole: roleID, CreatedAt: at}).Error
+}
+
+func (r *roleRepo) Unassign(ctx context.Context, userID, roleID uint) error {
+	return r.db.WithContext(ctx).
+		Where("id_user = ? AND id_role = ?", userID, roleID).
+		Delete(&models.UserRole{}).Error
+}
+
+func (r *roleRepo) CountUsers(ctx context.Context, roleID uint) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Model(&models.UserRole{}).
+		Where("id_role = ?", roleID).
+		Count(&n).Error
+	return n, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	FindByID(ctx context.Context, id string) (*models.Session, error)
+	// ListActiveByUserID returns sessions that are neither revoked nor expired
+	ListActiveByUserID(ctx context.Context, userID uint, now time.Time) ([]*models.Session, error)
+	Update(ctx context.Context, session *models.Session) error
+	Revoke(ctx context.Context, id string, at time.Time) error
+	RevokeByUserID(ctx context.Context, userID uint, at time.Time) error
+}
+
+type sessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepo{db: db}
+}
+
+func (r *sessionRepo) Create(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *sessionRepo) FindByID(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepo) ListActiveByUserID(ctx context.Context, userID uint, now time.Time) ([]*models.Session, error) {
+	var list []*models.Session
+	err := r.db.WithContext(ctx).
+		Where("id_user = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *sessionRepo) Update(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+func (r *sessionRepo) Revoke(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *sessionRepo) RevokeByUserID(ctx context.Context, userID uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id_user = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
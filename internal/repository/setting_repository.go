@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettingRepository interface {
+	// Get returns fallback when the setting has never been set
+	Get(ctx context.Context, name, fallback string) (string, error)
+	Set(ctx context.Context, name, value string, at time.Time) error
+}
+
+type settingRepo struct {
+	db *gorm.DB
+}
+
+func NewSettingRepository(db *gorm.DB) SettingRepository {
+	return &settingRepo{db: db}
+}
+
+func (r *settingRepo) Get(ctx context.Context, name, fallback string) (string, error) {
+	var setting models.Setting
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fallback, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return setting.Value, nil
+}
+
+func (r *settingRepo) Set(ctx context.Context, name, value string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+		}).
+		Create(&models.Setting{Name: name, Value: value, UpdatedAt: at}).Error
+}
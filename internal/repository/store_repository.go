@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 
-	"FinalTask/config"
 	"FinalTask/internal/models"
 
 	"gorm.io/gorm"
@@ -22,19 +21,20 @@ type StoreRepository interface {
 	Update(ctx context.Context, store *models.Toko) error
 }
 
-type storeRepo struct{}
+type storeRepo struct {
+	db *gorm.DB
+}
 
-func NewStoreRepository() StoreRepository {
-	return &storeRepo{}
+func NewStoreRepository(db *gorm.DB) StoreRepository {
+	return &storeRepo{db: db}
 }
 
 func (r *storeRepo) FindByUserID(ctx context.Context, userID uint) (*models.Toko, error) {
 	var store models.Toko
-	err := config.DB.WithContext(ctx).
+	err := r.db.WithContext(ctx).
 		Preload("Produk", func(db *gorm.DB) *gorm.DB {
 			return db.Preload("FotoProduk")
 		}).
-		Preload("User").
 		Where("id_user = ?", userID).
 		First(&store).Error
 	return &store, err
@@ -42,30 +42,30 @@ func (r *storeRepo) FindByUserID(ctx context.Context, userID uint) (*models.Toko
 
 func (r *storeRepo) FindByID(ctx context.Context, id uint) (*models.Toko, error) {
 	var store models.Toko
-	err := config.DB.WithContext(ctx).
-		Preload("Produk", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("FotoProduk")
-		}).
-		Preload("User").
+	err := r.db.WithContext(ctx).
+		Preload("Produk", publishedProducts).
 		First(&store, id).Error
 	return &store, err
 }
 
 func (r *storeRepo) List(ctx context.Context) ([]*models.Toko, error) {
 	var stores []*models.Toko
-	err := config.DB.WithContext(ctx).
-		Preload("Produk", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("FotoProduk")
-		}).
-		Preload("User").
+	err := r.db.WithContext(ctx).
+		Preload("Produk", publishedProducts).
 		Find(&stores).Error
 	return stores, err
 }
 
+// publishedProducts: halaman toko publik hanya menampilkan produk published,
+// FindByUserID (toko milik sendiri) tetap memuat draft
+func publishedProducts(db *gorm.DB) *gorm.DB {
+	return db.Where("status = ?", models.ProdukPublished).Preload("FotoProduk")
+}
+
 func (r *storeRepo) Create(ctx context.Context, store *models.Toko) error {
-	return config.DB.WithContext(ctx).Create(store).Error
+	return r.db.WithContext(ctx).Create(store).Error
 }
 
 func (r *storeRepo) Update(ctx context.Context, store *models.Toko) error {
-	return config.DB.WithContext(ctx).Save(store).Error
+	return r.db.WithContext(ctx).Save(store).Error
 }
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"time"
 
-	"FinalTask/config"
 	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
 )
 
 // TransactionRepository defines methods for handling transactions and detail rows
@@ -12,29 +14,48 @@ type TransactionRepository interface {
 	Create(ctx context.Context, trx *models.Trx) error
 	ListByUserID(ctx context.Context, userID uint, offset, limit int) ([]*models.Trx, error)
 	FindByID(ctx context.Context, userID, id uint) (*models.Trx, error)
+	// ListByStoreID and FindByStoreID return orders with at least one line
+	// sold by the store (store API keys)
+	ListByStoreID(ctx context.Context, storeID uint, offset, limit int) ([]*models.Trx, error)
+	FindByStoreID(ctx context.Context, storeID, id uint) (*models.Trx, error)
+	// ListAll and FindAnyByID ignore the owner (staff with order:read)
+	ListAll(ctx context.Context, offset, limit int) ([]*models.Trx, error)
+	FindAnyByID(ctx context.Context, id uint) (*models.Trx, error)
 
 	// Methods needed by service layer
 	CreateDetail(ctx context.Context, detail *models.DetailTrx) error
 	Update(ctx context.Context, trx *models.Trx) error
+
+	// Status pesanan: UpdateStatus returns false when the status is no
+	// longer from (changed concurrently)
+	UpdateStatus(ctx context.Context, id uint, from, to string) (bool, error)
+	CreateEvent(ctx context.Context, event *models.OrderEvent) error
+
+	// Refund: paling banyak satu per transaksi
+	CreateRefund(ctx context.Context, refund *models.Refund) error
+	FindRefund(ctx context.Context, trxID uint) (*models.Refund, error)
+	UpdateRefund(ctx context.Context, refund *models.Refund) error
 }
 
 // transactionRepo is concrete implementation of TransactionRepository
-type transactionRepo struct{}
+type transactionRepo struct {
+	db *gorm.DB
+}
 
 // NewTransactionRepository constructs a TransactionRepository
-func NewTransactionRepository() TransactionRepository {
-	return &transactionRepo{}
+func NewTransactionRepository(db *gorm.DB) TransactionRepository {
+	return &transactionRepo{db: db}
 }
 
 // Create inserts a new Trx record
 func (r *transactionRepo) Create(ctx context.Context, trx *models.Trx) error {
-	return config.DB.WithContext(ctx).Create(trx).Error
+	return r.db.WithContext(ctx).Create(trx).Error
 }
 
 // ListByUserID returns a paginated list of Trx for a user
 func (r *transactionRepo) ListByUserID(ctx context.Context, userID uint, offset, limit int) ([]*models.Trx, error) {
 	var list []*models.Trx
-	err := config.DB.WithContext(ctx).
+	err := r.db.WithContext(ctx).
 		Where("id_user = ?", userID).
 		Offset(offset).
 		Limit(limit).
@@ -46,19 +67,114 @@ func (r *transactionRepo) ListByUserID(ctx context.Context, userID uint, offset,
 // FindByID retrieves a single Trx by userID and trx ID
 func (r *transactionRepo) FindByID(ctx context.Context, userID, id uint) (*models.Trx, error) {
 	var trx models.Trx
-	err := config.DB.WithContext(ctx).
+	err := r.db.WithContext(ctx).
 		Where("id = ? AND id_user = ?", id, userID).
 		Preload("DetailTrx").
+		Preload("Events", orderedEvents).
+		Preload("Refund").
+		First(&trx).Error
+	return &trx, err
+}
+
+// storeLines limits a Trx query to orders containing a line of storeID
+func (r *transactionRepo) storeLines(ctx context.Context, storeID uint) *gorm.DB {
+	lines := r.db.WithContext(ctx).Model(&models.DetailTrx{}).Select("id_trx").Where("id_toko = ?", storeID)
+	return r.db.WithContext(ctx).Where("id IN (?)", lines)
+}
+
+// ListByStoreID returns a paginated list of orders placed with a store, newest first
+func (r *transactionRepo) ListByStoreID(ctx context.Context, storeID uint, offset, limit int) ([]*models.Trx, error) {
+	var list []*models.Trx
+	err := r.storeLines(ctx, storeID).
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Preload("DetailTrx").
+		Find(&list).Error
+	return list, err
+}
+
+// FindByStoreID retrieves a single order placed with a store
+func (r *transactionRepo) FindByStoreID(ctx context.Context, storeID, id uint) (*models.Trx, error) {
+	var trx models.Trx
+	err := r.storeLines(ctx, storeID).
+		Where("id = ?", id).
+		Preload("DetailTrx").
+		Preload("Events", orderedEvents).
+		Preload("Refund").
+		First(&trx).Error
+	return &trx, err
+}
+
+// ListAll returns a paginated list of every Trx, newest first
+func (r *transactionRepo) ListAll(ctx context.Context, offset, limit int) ([]*models.Trx, error) {
+	var list []*models.Trx
+	err := r.db.WithContext(ctx).
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Preload("DetailTrx").
+		Find(&list).Error
+	return list, err
+}
+
+// FindAnyByID retrieves a single Trx regardless of its owner
+func (r *transactionRepo) FindAnyByID(ctx context.Context, id uint) (*models.Trx, error) {
+	var trx models.Trx
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Preload("DetailTrx").
+		Preload("Events", orderedEvents).
+		Preload("Refund").
 		First(&trx).Error
 	return &trx, err
 }
 
 // CreateDetail inserts a new DetailTrx record (transaction detail)
 func (r *transactionRepo) CreateDetail(ctx context.Context, detail *models.DetailTrx) error {
-	return config.DB.WithContext(ctx).Create(detail).Error
+	return r.db.WithContext(ctx).Create(detail).Error
 }
 
 // Update saves changes to an existing Trx record (e.g., update total & invoice)
 func (r *transactionRepo) Update(ctx context.Context, trx *models.Trx) error {
-	return config.DB.WithContext(ctx).Save(trx).Error
+	return r.db.WithContext(ctx).Save(trx).Error
+}
+
+// UpdateStatus moves a Trx from one status to another in a single
+// conditional statement, so two concurrent changes cannot both win
+func (r *transactionRepo) UpdateStatus(ctx context.Context, id uint, from, to string) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.Trx{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "updated_at": time.Now()})
+	return res.RowsAffected == 1, res.Error
+}
+
+// CreateEvent appends an entry to the order timeline
+func (r *transactionRepo) CreateEvent(ctx context.Context, event *models.OrderEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// orderedEvents: timeline diurutkan dari event paling awal
+func orderedEvents(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
+
+// CreateRefund inserts the refund of a transaction
+func (r *transactionRepo) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	return r.db.WithContext(ctx).Create(refund).Error
+}
+
+// FindRefund returns the refund of a transaction (gorm.ErrRecordNotFound if none)
+func (r *transactionRepo) FindRefund(ctx context.Context, trxID uint) (*models.Refund, error) {
+	var refund models.Refund
+	if err := r.db.WithContext(ctx).Where("id_trx = ?", trxID).First(&refund).Error; err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// UpdateRefund saves changes to a refund (e.g. completing it)
+func (r *transactionRepo) UpdateRefund(ctx context.Context, refund *models.Refund) error {
+	return r.db.WithContext(ctx).Save(refund).Error
 }
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	// ReplaceForUser deletes every old code of the user and stores the new hashes
+	ReplaceForUser(ctx context.Context, userID uint, hashes []string, at time.Time) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+	// Use marks an unused code as used; false when no such code exists
+	Use(ctx context.Context, userID uint, hash string, at time.Time) (bool, error)
+	CountUnused(ctx context.Context, userID uint) (int64, error)
+}
+
+type recoveryCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepo{db: db}
+}
+
+func (r *recoveryCodeRepo) ReplaceForUser(ctx context.Context, userID uint, hashes []string, at time.Time) error {
+	if err := r.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	codes := make([]*models.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, &models.RecoveryCode{IDUser: userID, CodeHash: hash, CreatedAt: at})
+	}
+	return r.db.WithContext(ctx).Create(&codes).Error
+}
+
+func (r *recoveryCodeRepo) DeleteByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("id_user = ?", userID).
+		Delete(&models.RecoveryCode{}).Error
+}
+
+func (r *recoveryCodeRepo) Use(ctx context.Context, userID uint, hash string, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("id_user = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *recoveryCodeRepo) CountUnused(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("id_user = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+type LoginChallengeRepository interface {
+	Create(ctx context.Context, challenge *models.LoginChallenge) error
+	FindByHash(ctx context.Context, hash string) (*models.LoginChallenge, error)
+	// ClaimAttempt uses up one code attempt while fewer than max were made;
+	// false means the limit is reached (atomic, see VerificationRepository)
+	ClaimAttempt(ctx context.Context, id uint, max int) (bool, error)
+	// MarkUsed is single-use: false when the challenge was already used
+	MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error)
+}
+
+type loginChallengeRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginChallengeRepository(db *gorm.DB) LoginChallengeRepository {
+	return &loginChallengeRepo{db: db}
+}
+
+func (r *loginChallengeRepo) Create(ctx context.Context, challenge *models.LoginChallenge) error {
+	return r.db.WithContext(ctx).Create(challenge).Error
+}
+
+func (r *loginChallengeRepo) FindByHash(ctx context.Context, hash string) (*models.LoginChallenge, error) {
+	var challenge models.LoginChallenge
+	if err := r.db.WithContext(ctx).
+		Where("token_hash = ?", hash).
+		First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *loginChallengeRepo) ClaimAttempt(ctx context.Context, id uint, max int) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.LoginChallenge{}).
+		Where("id = ? AND attempts < ?", id, max).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *loginChallengeRepo) MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.LoginChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
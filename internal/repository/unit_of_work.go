@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories groups every repository bound to the same *gorm.DB handle
+type Repositories struct {
+	User          UserRepository
+	Store         StoreRepository
+	Address       AddressRepository
+	Category      CategoryRepository
+	Product       ProductRepository
+	Transaction   TransactionRepository
+	RefreshToken  RefreshTokenRepository
+	Session       SessionRepository
+	PasswordReset PasswordResetRepository
+	Verification  VerificationRepository
+	LoginAttempt  LoginAttemptRepository
+	Role          RoleRepository
+	APIKey        APIKeyRepository
+	RecoveryCode  RecoveryCodeRepository
+	Challenge     LoginChallengeRepository
+	Setting       SettingRepository
+	Identity      UserIdentityRepository
+	OIDCState     OIDCStateRepository
+}
+
+// NewRepositories builds all repositories on top of db (a pool or a tx)
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		User:          NewUserRepository(db),
+		Store:         NewStoreRepository(db),
+		Address:       NewAddressRepository(db),
+		Category:      NewCategoryRepository(db),
+		Product:       NewProductRepository(db),
+		Transaction:   NewTransactionRepository(db),
+		RefreshToken:  NewRefreshTokenRepository(db),
+		Session:       NewSessionRepository(db),
+		PasswordReset: NewPasswordResetRepository(db),
+		Verification:  NewVerificationRepository(db),
+		LoginAttempt:  NewLoginAttemptRepository(db),
+		Role:          NewRoleRepository(db),
+		APIKey:        NewAPIKeyRepository(db),
+		RecoveryCode:  NewRecoveryCodeRepository(db),
+		Challenge:     NewLoginChallengeRepository(db),
+		Setting:       NewSettingRepository(db),
+		Identity:      NewUserIdentityRepository(db),
+		OIDCState:     NewOIDCStateRepository(db),
+	}
+}
+
+// UnitOfWork runs multi-repository flows atomically
+type UnitOfWork interface {
+	// WithTx opens a DB transaction and hands fn repositories bound to it.
+	// The transaction commits when fn returns nil and rolls back otherwise.
+	WithTx(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) WithTx(ctx context.Context, fn func(repos *Repositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	ListByUserID(ctx context.Context, userID uint) ([]*models.UserIdentity, error)
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	// TouchLogin records the last login and the email the provider reported
+	TouchLogin(ctx context.Context, id uint, email string, at time.Time) error
+}
+
+type userIdentityRepo struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepo{db: db}
+}
+
+func (r *userIdentityRepo) FindByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepo) ListByUserID(ctx context.Context, userID uint) ([]*models.UserIdentity, error) {
+	var list []*models.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("id_user = ?", userID).
+		Order("created_at ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *userIdentityRepo) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *userIdentityRepo) TouchLogin(ctx context.Context, id uint, email string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"email": email, "last_login_at": at}).Error
+}
+
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *models.OIDCState) error
+	FindByHash(ctx context.Context, hash string) (*models.OIDCState, error)
+	// MarkUsed is single-use: false when the callback was already handled
+	MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error)
+	// DeleteExpired removes states older than before
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+type oidcStateRepo struct {
+	db *gorm.DB
+}
+
+func NewOIDCStateRepository(db *gorm.DB) OIDCStateRepository {
+	return &oidcStateRepo{db: db}
+}
+
+func (r *oidcStateRepo) Create(ctx context.Context, state *models.OIDCState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+func (r *oidcStateRepo) FindByHash(ctx context.Context, hash string) (*models.OIDCState, error) {
+	var state models.OIDCState
+	if err := r.db.WithContext(ctx).
+		Where("state_hash = ?", hash).
+		First(&state).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *oidcStateRepo) MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.OIDCState{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *oidcStateRepo) DeleteExpired(ctx context.Context, before time.Time) error {
+	return r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Delete(&models.OIDCState{}).Error
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"time"
 
-	"FinalTask/config"
 	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
 )
 
 type UserRepository interface {
@@ -13,18 +15,31 @@ type UserRepository interface {
 	FindByPhone(ctx context.Context, phone string) (*models.User, error)
 	Create(ctx context.Context, user *models.User) error
 	Update(ctx context.Context, user *models.User) error
+	// RecordLoginFailure atomically bumps the failed-login counter
+	RecordLoginFailure(ctx context.Context, id uint, at time.Time) error
+	LockUntil(ctx context.Context, id uint, until time.Time) error
+	// ResetLoginFailures clears the counter and any lock
+	ResetLoginFailures(ctx context.Context, id uint) error
+	// SetTOTP stores the 2FA secret; enabledAt nil means setup is pending.
+	// An empty secret disables 2FA.
+	SetTOTP(ctx context.Context, id uint, secret string, enabledAt *time.Time) error
+	// UseTOTPStep records a used TOTP period; false when it (or a later one)
+	// was already used, so a code cannot be replayed
+	UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
 }
 
-type userRepo struct{}
+type userRepo struct {
+	db *gorm.DB
+}
 
-func NewUserRepository() UserRepository {
-	return &userRepo{}
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepo{db: db}
 }
 
 // FindByID mencari user berdasarkan ID
 func (r *userRepo) FindByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	if err := config.DB.WithContext(ctx).First(&user, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -33,7 +48,7 @@ func (r *userRepo) FindByID(ctx context.Context, id uint) (*models.User, error)
 // FindByEmail mencari user berdasarkan email
 func (r *userRepo) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	if err := config.DB.WithContext(ctx).
+	if err := r.db.WithContext(ctx).
 		Where("email = ?", email).
 		First(&user).Error; err != nil {
 		return nil, err
@@ -44,7 +59,7 @@ func (r *userRepo) FindByEmail(ctx context.Context, email string) (*models.User,
 // FindByPhone mencari user berdasarkan nomor telepon
 func (r *userRepo) FindByPhone(ctx context.Context, phone string) (*models.User, error) {
 	var user models.User
-	if err := config.DB.WithContext(ctx).
+	if err := r.db.WithContext(ctx).
 		Where("no_telp = ?", phone).
 		First(&user).Error; err != nil {
 		return nil, err
@@ -54,10 +69,62 @@ func (r *userRepo) FindByPhone(ctx context.Context, phone string) (*models.User,
 
 // Create membuat user baru
 func (r *userRepo) Create(ctx context.Context, user *models.User) error {
-	return config.DB.WithContext(ctx).Create(user).Error
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
 // Update memperbarui data user
 func (r *userRepo) Update(ctx context.Context, user *models.User) error {
-	return config.DB.WithContext(ctx).Save(user).Error
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+// RecordLoginFailure menambah counter gagal login secara atomik
+func (r *userRepo) RecordLoginFailure(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"failed_login_count":   gorm.Expr("failed_login_count + 1"),
+			"last_failed_login_at": at,
+		}).Error
+}
+
+// LockUntil mengunci akun sampai waktu tertentu
+func (r *userRepo) LockUntil(ctx context.Context, id uint, until time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("locked_until", until).Error
+}
+
+// ResetLoginFailures menghapus counter gagal login dan kunci akun
+func (r *userRepo) ResetLoginFailures(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"failed_login_count":   0,
+			"last_failed_login_at": nil,
+			"locked_until":         nil,
+		}).Error
+}
+
+// SetTOTP menyimpan secret 2FA; periode terakhir direset setiap secret berganti
+func (r *userRepo) SetTOTP(ctx context.Context, id uint, secret string, enabledAt *time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"totp_secret":     secret,
+			"totp_enabled_at": enabledAt,
+			"totp_last_step":  0,
+		}).Error
+}
+
+// UseTOTPStep menandai periode TOTP sudah dipakai secara atomik
+func (r *userRepo) UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	return res.RowsAffected == 1, res.Error
 }
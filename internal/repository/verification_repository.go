@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type VerificationRepository interface {
+	Create(ctx context.Context, code *models.VerificationCode) error
+	// FindActive returns the newest unused code of the user for a channel
+	FindActive(ctx context.Context, userID uint, channel string) (*models.VerificationCode, error)
+	// CountSince counts codes sent on a channel since the given time (throttling)
+	CountSince(ctx context.Context, userID uint, channel string, since time.Time) (int64, error)
+	// ClaimAttempt uses up one attempt while fewer than max were made; false
+	// means the limit is reached. Atomic, so parallel guesses cannot exceed it.
+	ClaimAttempt(ctx context.Context, id uint, max int) (bool, error)
+	// MarkUsed consumes an unused code; false means it was already used
+	MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error)
+	InvalidateByChannel(ctx context.Context, userID uint, channel string, at time.Time) error
+}
+
+type verificationRepo struct {
+	db *gorm.DB
+}
+
+func NewVerificationRepository(db *gorm.DB) VerificationRepository {
+	return &verificationRepo{db: db}
+}
+
+func (r *verificationRepo) Create(ctx context.Context, code *models.VerificationCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+func (r *verificationRepo) FindActive(ctx context.Context, userID uint, channel string) (*models.VerificationCode, error) {
+	var code models.VerificationCode
+	if err := r.db.WithContext(ctx).
+		Where("id_user = ? AND channel = ? AND used_at IS NULL", userID, channel).
+		Order("created_at DESC, id DESC").
+		First(&code).Error; err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *verificationRepo) CountSince(ctx context.Context, userID uint, channel string, since time.Time) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Model(&models.VerificationCode{}).
+		Where("id_user = ? AND channel = ? AND created_at >= ?", userID, channel, since).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *verificationRepo) ClaimAttempt(ctx context.Context, id uint, max int) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.VerificationCode{}).
+		Where("id = ? AND attempts < ?", id, max).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *verificationRepo) MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.VerificationCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *verificationRepo) InvalidateByChannel(ctx context.Context, userID uint, channel string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.VerificationCode{}).
+		Where("id_user = ? AND channel = ? AND used_at IS NULL", userID, channel).
+		Update("used_at", at).Error
+}
@@ -2,21 +2,19 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"net/http"
 	"time"
 
+	"FinalTask/internal/apperr"
 	"FinalTask/internal/models"
 	"FinalTask/internal/repository"
 )
 
 // ==== Request DTO ====
 type CreateAddressRequest struct {
-	JudulAlamat  string `json:"judul_alamat"`
-	NamaPenerima string `json:"nama_penerima"`
-	NoTelp       string `json:"no_telp"`
-	DetailAlamat string `json:"detail_alamat"`
+	JudulAlamat  string `json:"judul_alamat" validate:"required,max=255"`
+	NamaPenerima string `json:"nama_penerima" validate:"required,max=255"`
+	NoTelp       string `json:"no_telp" validate:"required,phone"`
+	DetailAlamat string `json:"detail_alamat" validate:"required,max=255"`
 }
 
 // ==== Interface ====
@@ -32,13 +30,17 @@ type AddressService interface {
 	GetRegenciesByProvince(ctx context.Context, provinceID string) (interface{}, error)
 }
 
+// Alamat milik user lain juga dilaporkan "not found"
+var ErrAddressNotFound = apperr.NotFound("address not found")
+
 // ==== Implementasi ====
 type addressService struct {
-	repo repository.AddressRepository
+	repo          repository.AddressRepository
+	regionBaseURL string
 }
 
-func NewAddressService(repo repository.AddressRepository) AddressService {
-	return &addressService{repo: repo}
+func NewAddressService(repo repository.AddressRepository, regionBaseURL string) AddressService {
+	return &addressService{repo: repo, regionBaseURL: regionBaseURL}
 }
 
 // ==== CRUD Alamat ====
@@ -63,9 +65,9 @@ func (s *addressService) List(ctx context.Context, userID uint) ([]*models.Alama
 }
 
 func (s *addressService) Update(ctx context.Context, userID, id uint, req CreateAddressRequest) (*models.Alamat, error) {
-	addr, err := s.repo.FindByID(ctx, id)
-	if err != nil || addr.IDUser != userID {
-		return nil, errors.New("address not found or unauthorized")
+	addr, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
 	}
 	addr.JudulAlamat = req.JudulAlamat
 	addr.NamaPenerima = req.NamaPenerima
@@ -79,45 +81,43 @@ func (s *addressService) Update(ctx context.Context, userID, id uint, req Create
 }
 
 func (s *addressService) Delete(ctx context.Context, userID, id uint) error {
-	addr, err := s.repo.FindByID(ctx, id)
-	if err != nil || addr.IDUser != userID {
-		return errors.New("address not found or unauthorized")
+	if _, err := s.findOwned(ctx, userID, id); err != nil {
+		return err
 	}
 	return s.repo.Delete(ctx, id)
 }
 
 func (s *addressService) GetByID(ctx context.Context, userID, id uint) (*models.Alamat, error) {
-	addr, err := s.repo.FindByID(ctx, id)
-	if err != nil || addr.IDUser != userID {
-		return nil, errors.New("address not found or unauthorized")
+	addr, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
 	}
 	return addr, nil
 }
 
-// ==== API Wilayah Indonesia ====
-func (s *addressService) GetProvinces(ctx context.Context) (interface{}, error) {
-	resp, err := http.Get("https://www.emsifa.com/api-wilayah-indonesia/api/provinces.json")
+func (s *addressService) findOwned(ctx context.Context, userID, id uint) (*models.Alamat, error) {
+	addr, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, apperr.FromDB(err, ErrAddressNotFound, nil)
+	}
+	if addr.IDUser != userID {
+		return nil, ErrAddressNotFound
 	}
-	defer resp.Body.Close()
+	return addr, nil
+}
 
+// ==== API Wilayah Indonesia ====
+func (s *addressService) GetProvinces(ctx context.Context) (interface{}, error) {
 	var provinces interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&provinces); err != nil {
+	if err := getRegionJSON(s.regionBaseURL+"/provinces.json", &provinces); err != nil {
 		return nil, err
 	}
 	return provinces, nil
 }
 
 func (s *addressService) GetRegenciesByProvince(ctx context.Context, provinceID string) (interface{}, error) {
-	resp, err := http.Get("https://www.emsifa.com/api-wilayah-indonesia/api/regencies/" + provinceID + ".json")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var regencies interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&regencies); err != nil {
+	if err := getRegionJSON(s.regionBaseURL+"/regencies/"+provinceID+".json", &regencies); err != nil {
 		return nil, err
 	}
 	return regencies, nil
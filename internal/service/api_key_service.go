@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+	"FinalTask/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// APIKeyPrefix menandai API key sehingga bisa dibedakan dari JWT
+	APIKeyPrefix = "ftk_"
+
+	maxAPIKeysPerStore    = 10
+	defaultAPIKeyLifetime = 90 // hari, batas atas ada di tag expires_in_days
+	apiKeyTouchInterval   = time.Minute
+)
+
+// CreateAPIKeyRequest is the body of POST /store/api-keys
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,dive,oneof=products:read products:write orders:read orders:write"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"` // default 90
+}
+
+// APIKeyInfo describes a key without its secret
+type APIKeyInfo struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKey is returned once on creation; Key is never shown again
+type CreatedAPIKey struct {
+	APIKeyInfo
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipal is who a valid API key authenticates as: the store owner,
+// limited to the key's scopes
+type APIKeyPrincipal struct {
+	KeyID   uint
+	StoreID uint
+	UserID  uint
+	Scopes  []string
+}
+
+var (
+	ErrInvalidAPIKey  = apperr.Unauthorized("API key tidak valid, kedaluwarsa atau sudah dicabut")
+	ErrAPIKeyNotFound = apperr.NotFound("API key tidak ditemukan")
+	ErrNoScope        = apperr.InvalidField("scopes", "minimal satu scope wajib dipilih")
+	ErrTooManyAPIKeys = apperr.Conflict(fmt.Sprintf("maksimal %d API key aktif per toko", maxAPIKeysPerStore))
+	ErrStoreNotFound  = apperr.NotFound("store not found")
+)
+
+// APIKeyService manages the API keys of the caller's Toko
+type APIKeyService interface {
+	List(ctx context.Context, userID uint) ([]APIKeyInfo, error)
+	Create(ctx context.Context, userID uint, req CreateAPIKeyRequest) (*CreatedAPIKey, error)
+	Revoke(ctx context.Context, userID, keyID uint) error
+	// Authenticate checks a plaintext key and records its last use
+	Authenticate(ctx context.Context, plain, ip string) (*APIKeyPrincipal, error)
+}
+
+type apiKeyService struct {
+	uow        repository.UnitOfWork
+	apiKeyRepo repository.APIKeyRepository
+	storeRepo  repository.StoreRepository
+}
+
+func NewAPIKeyService(
+	uow repository.UnitOfWork,
+	apiKeyRepo repository.APIKeyRepository,
+	storeRepo repository.StoreRepository,
+) APIKeyService {
+	return &apiKeyService{
+		uow:        uow,
+		apiKeyRepo: apiKeyRepo,
+		storeRepo:  storeRepo,
+	}
+}
+
+func (s *apiKeyService) List(ctx context.Context, userID uint) ([]APIKeyInfo, error) {
+	store, err := s.myStore(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.apiKeyRepo.ListByStoreID(ctx, store.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]APIKeyInfo, 0, len(list))
+	for _, key := range list {
+		result = append(result, toAPIKeyInfo(key))
+	}
+	return result, nil
+}
+
+func (s *apiKeyService) Create(ctx context.Context, userID uint, req CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	// Nama, scope & masa berlaku sudah divalidasi lewat tag di handler
+	name := strings.TrimSpace(req.Name)
+	scopes := normalizeScopes(req.Scopes)
+	if len(scopes) == 0 {
+		return nil, ErrNoScope
+	}
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPIKeyLifetime
+	}
+
+	store, err := s.myStore(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	plain := APIKeyPrefix + secret
+	now := time.Now()
+	key := &models.APIKey{
+		IDToko:    store.ID,
+		IDUser:    userID,
+		Name:      name,
+		Prefix:    plain[:len(APIKeyPrefix)+8],
+		KeyHash:   hash,
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: now.AddDate(0, 0, days),
+		CreatedAt: now,
+	}
+	err = s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		active, err := repos.APIKey.CountActive(ctx, store.ID, now)
+		if err != nil {
+			return err
+		}
+		if active >= maxAPIKeysPerStore {
+			return ErrTooManyAPIKeys
+		}
+		return repos.APIKey.Create(ctx, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{APIKeyInfo: toAPIKeyInfo(key), Key: plain}, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, userID, keyID uint) error {
+	store, err := s.myStore(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.apiKeyRepo.FindByID(ctx, store.ID, keyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	return s.apiKeyRepo.Revoke(ctx, keyID, time.Now())
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, plain, ip string) (*APIKeyPrincipal, error) {
+	if !strings.HasPrefix(plain, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	// Hash disimpan dari bagian acak saja, prefix hanya penanda
+	key, err := s.apiKeyRepo.FindByHash(ctx, utils.HashToken(strings.TrimPrefix(plain, APIKeyPrefix)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+	// Key tetap milik pemilik toko saat ini
+	store, err := s.storeRepo.FindByID(ctx, key.IDToko)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now, ip, apiKeyTouchInterval); err != nil {
+		return nil, err
+	}
+	return &APIKeyPrincipal{
+		KeyID:   key.ID,
+		StoreID: store.ID,
+		UserID:  store.IDUser,
+		Scopes:  key.ScopeList(),
+	}, nil
+}
+
+func (s *apiKeyService) myStore(ctx context.Context, userID uint) (*models.Toko, error) {
+	store, err := s.storeRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStoreNotFound
+		}
+		return nil, err
+	}
+	return store, nil
+}
+
+// normalizeScopes removes duplicates and unknown scopes, keeping the
+// canonical order of models.APIKeyScopes
+func normalizeScopes(requested []string) []string {
+	wanted := map[string]bool{}
+	for _, scope := range requested {
+		wanted[strings.TrimSpace(scope)] = true
+	}
+	scopes := make([]string, 0, len(wanted))
+	for _, s := range models.APIKeyScopes {
+		if wanted[s] {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func toAPIKeyInfo(key *models.APIKey) APIKeyInfo {
+	return APIKeyInfo{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
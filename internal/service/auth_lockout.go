@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+)
+
+// Gagal login pertama tidak diberi jeda; setelahnya jeda berlipat dua
+const loginFreeAttempts = 3
+
+// LoginAuditEntry is one row of the login audit trail as shown to admins
+type LoginAuditEntry struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginBlockedError is returned when a login is refused before the password
+// is even checked (backoff, locked account or blocked IP)
+type LoginBlockedError struct {
+	Reason     string // models.LoginBackoff, models.LoginLocked atau models.LoginIPBlocked
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	secs := int(e.RetryAfter.Seconds()) + 1
+	switch e.Reason {
+	case models.LoginLocked:
+		return fmt.Sprintf("akun terkunci sementara karena terlalu banyak percobaan login, coba lagi dalam %d detik", secs)
+	case models.LoginIPBlocked:
+		return fmt.Sprintf("terlalu banyak percobaan login dari alamat IP ini, coba lagi dalam %d detik", secs)
+	default:
+		return fmt.Sprintf("terlalu banyak percobaan login gagal, coba lagi dalam %d detik", secs)
+	}
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return apperr.Throttled(e.Error(), e.RetryAfter)
+}
+
+var ErrInvalidCredentials = apperr.Unauthorized("email atau password salah")
+
+// checkIPLimit memblokir IP yang gagal login terlalu sering dalam satu window
+func (s *authService) checkIPLimit(ctx context.Context, ip string, now time.Time) error {
+	since := now.Add(-s.authCfg.LoginIPWindow)
+	failures, err := s.loginAttemptRepo.CountFailuresByIP(ctx, ip, since)
+	if err != nil {
+		return err
+	}
+	if failures < int64(s.authCfg.LoginIPMaxFailures) {
+		return nil
+	}
+	retry := s.authCfg.LoginIPWindow
+	if oldest, err := s.loginAttemptRepo.OldestFailureByIP(ctx, ip, since); err == nil {
+		retry = oldest.CreatedAt.Add(s.authCfg.LoginIPWindow).Sub(now)
+	}
+	return &LoginBlockedError{Reason: models.LoginIPBlocked, RetryAfter: retry}
+}
+
+// checkAccountLimit menolak login selama akun terkunci atau masih dalam jeda backoff
+func (s *authService) checkAccountLimit(ctx context.Context, user *models.User, now time.Time) error {
+	if user.IsLocked(now) {
+		return &LoginBlockedError{Reason: models.LoginLocked, RetryAfter: user.LockedUntil.Sub(now)}
+	}
+	if user.LockedUntil != nil {
+		// Masa kunci sudah lewat, hitungan dimulai dari awal
+		if err := s.userRepo.ResetLoginFailures(ctx, user.ID); err != nil {
+			return err
+		}
+		user.FailedLoginCount = 0
+		user.LastFailedLoginAt = nil
+		user.LockedUntil = nil
+	}
+	if user.LastFailedLoginAt != nil {
+		if wait := user.LastFailedLoginAt.Add(s.loginBackoff(user.FailedLoginCount)).Sub(now); wait > 0 {
+			return &LoginBlockedError{Reason: models.LoginBackoff, RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// registerFailure menambah counter gagal login dan mengunci akun bila
+// batas LoginMaxAttempts tercapai
+func (s *authService) registerFailure(ctx context.Context, user *models.User, now time.Time) error {
+	if err := s.userRepo.RecordLoginFailure(ctx, user.ID, now); err != nil {
+		return err
+	}
+	// Baca ulang supaya request paralel ikut terhitung
+	fresh, err := s.userRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if fresh.FailedLoginCount < s.authCfg.LoginMaxAttempts {
+		return nil
+	}
+	if err := s.userRepo.LockUntil(ctx, user.ID, now.Add(s.authCfg.LoginLockout)); err != nil {
+		return err
+	}
+	log.Printf("⚠️ akun %d dikunci %s setelah %d kali gagal login", user.ID, s.authCfg.LoginLockout, fresh.FailedLoginCount)
+	return &LoginBlockedError{Reason: models.LoginLocked, RetryAfter: s.authCfg.LoginLockout}
+}
+
+// loginBackoff menghitung jeda setelah failures kali gagal: base, 2x, 4x, ... maksimal LoginBackoffMax
+func (s *authService) loginBackoff(failures int) time.Duration {
+	if failures < loginFreeAttempts {
+		return 0
+	}
+	shift := failures - loginFreeAttempts
+	if shift > 30 {
+		shift = 30
+	}
+	d := s.authCfg.LoginBackoffBase << shift
+	if d > s.authCfg.LoginBackoffMax || d <= 0 {
+		return s.authCfg.LoginBackoffMax
+	}
+	return d
+}
+
+// recordAttempt menulis audit login; kegagalan menulis tidak menggagalkan login
+func (s *authService) recordAttempt(ctx context.Context, userID *uint, email string, client ClientInfo, reason string) {
+	attempt := &models.LoginAttempt{
+		IDUser:    userID,
+		Email:     truncate(email, 255),
+		IPAddress: client.IPAddress,
+		UserAgent: truncate(client.UserAgent, 255),
+		Success:   reason == models.LoginOK,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if err := s.loginAttemptRepo.Create(ctx, attempt); err != nil {
+		log.Printf("⚠️ gagal mencatat audit login %s: %v", email, err)
+	}
+}
+
+// UnlockAccount membuka kunci akun dan menghapus counter gagal login (admin)
+func (s *authService) UnlockAccount(ctx context.Context, userID uint) error {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return apperr.FromDB(err, ErrUserNotFound, nil)
+	}
+	return s.userRepo.ResetLoginFailures(ctx, userID)
+}
+
+// LoginHistory mengembalikan audit login terbaru milik user (admin)
+func (s *authService) LoginHistory(ctx context.Context, userID uint, limit int) ([]LoginAuditEntry, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	rows, err := s.loginAttemptRepo.ListByUserID(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]LoginAuditEntry, 0, len(rows))
+	for _, r := range rows {
+		list = append(list, LoginAuditEntry{
+			ID:        r.ID,
+			Email:     r.Email,
+			IPAddress: r.IPAddress,
+			UserAgent: r.UserAgent,
+			Success:   r.Success,
+			Reason:    r.Reason,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	return list, nil
+}
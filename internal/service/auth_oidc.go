@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/oidc"
+	"FinalTask/internal/repository"
+	"FinalTask/utils"
+
+	"gorm.io/gorm"
+)
+
+// OIDCProviderInfo is one entry of the "Login with ..." list
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCCallbackRequest is what the provider sends back to the redirect URL
+type OIDCCallbackRequest struct {
+	Code             string `query:"code"`
+	State            string `query:"state"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+}
+
+var (
+	ErrUnknownProvider       = apperr.NotFound("provider login tidak dikenal")
+	ErrInvalidOIDCState      = apperr.BadRequest("sesi login eksternal tidak valid atau kedaluwarsa, silakan ulangi")
+	ErrOIDCDenied            = apperr.Unauthorized("login dibatalkan atau ditolak oleh penyedia identitas")
+	ErrOIDCEmailNotVerified  = apperr.Forbidden("penyedia identitas tidak mengirim email yang terverifikasi")
+	ErrOIDCAccountUnverified = apperr.Conflict("sudah ada akun dengan email ini yang belum diverifikasi, login dengan password lalu verifikasi email terlebih dahulu")
+)
+
+func (s *authService) OIDCProviders() []OIDCProviderInfo {
+	list := []OIDCProviderInfo{}
+	for _, p := range s.oidcProviders.List() {
+		list = append(list, OIDCProviderInfo{Name: p.Name(), DisplayName: p.DisplayName()})
+	}
+	return list
+}
+
+// OIDCAuthURL menyimpan state, nonce dan PKCE verifier lalu mengembalikan
+// URL login di provider. Hanya hash state yang disimpan.
+func (s *authService) OIDCAuthURL(ctx context.Context, name string) (string, error) {
+	provider, ok := s.oidcProviders.Get(name)
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	state, stateHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := s.oidcStateRepo.DeleteExpired(ctx, now); err != nil {
+		log.Printf("⚠️ gagal menghapus state OIDC kedaluwarsa: %v", err)
+	}
+	if err := s.oidcStateRepo.Create(ctx, &models.OIDCState{
+		StateHash:    stateHash,
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(s.oidcCfg.StateTTL),
+		CreatedAt:    now,
+	}); err != nil {
+		return "", err
+	}
+	url, err := provider.AuthCodeURL(ctx, state, nonce, oidc.PKCEChallenge(verifier))
+	if err != nil {
+		log.Printf("⚠️ discovery OIDC %s gagal: %v", name, err)
+		return "", err
+	}
+	return url, nil
+}
+
+// OIDCCallback menyelesaikan login dari provider: state dipakai sekali,
+// code ditukar dengan id_token yang diverifikasi, identitas ditautkan ke
+// User (dibuat baru bila perlu) lalu login diteruskan seperti login
+// password, termasuk challenge 2FA
+func (s *authService) OIDCCallback(ctx context.Context, name string, req OIDCCallbackRequest, client ClientInfo) (*LoginResult, error) {
+	provider, ok := s.oidcProviders.Get(name)
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	now := time.Now()
+	if err := s.checkIPLimit(ctx, client.IPAddress, now); err != nil {
+		return nil, err
+	}
+
+	state, err := s.consumeOIDCState(ctx, name, req.State, now)
+	if err != nil {
+		if errors.Is(err, ErrInvalidOIDCState) {
+			s.recordAttempt(ctx, nil, "", client, models.LoginBadOIDC)
+		}
+		return nil, err
+	}
+	if req.Error != "" {
+		return nil, fmt.Errorf("%w (%s)", ErrOIDCDenied, req.Error)
+	}
+	if req.Code == "" {
+		return nil, ErrInvalidOIDCState
+	}
+
+	claims, err := provider.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("⚠️ login OIDC %s gagal: %v", name, err)
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			s.recordAttempt(ctx, nil, "", client, models.LoginBadOIDC)
+		}
+		return nil, err
+	}
+
+	user, err := s.linkIdentity(ctx, name, claims, now)
+	if err != nil {
+		return nil, err
+	}
+	// Kunci akun (brute-force password) sengaja tidak dicek: login lewat
+	// provider tidak memakai password sehingga pemilik akun tetap bisa masuk
+	return s.finishLogin(ctx, user, client)
+}
+
+func (s *authService) consumeOIDCState(ctx context.Context, name, plain string, now time.Time) (*models.OIDCState, error) {
+	if plain == "" {
+		return nil, ErrInvalidOIDCState
+	}
+	state, err := s.oidcStateRepo.FindByHash(ctx, utils.HashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+	if state.Provider != name || state.UsedAt != nil || now.After(state.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+	used, err := s.oidcStateRepo.MarkUsed(ctx, state.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidOIDCState
+	}
+	return state, nil
+}
+
+// linkIdentity mencari user dari (provider, sub). Identitas baru ditautkan
+// lewat email yang diverifikasi provider; bila belum ada user dengan email
+// itu, user + toko dibuat seperti Register.
+func (s *authService) linkIdentity(ctx context.Context, provider string, claims *oidc.Claims, now time.Time) (*models.User, error) {
+	var user *models.User
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		identity, err := repos.Identity.FindByProviderSubject(ctx, provider, claims.Subject)
+		if err == nil {
+			if err := repos.Identity.TouchLogin(ctx, identity.ID, truncate(claims.Email, 255), now); err != nil {
+				return err
+			}
+			user, err = repos.User.FindByID(ctx, identity.IDUser)
+			return err
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if claims.Email == "" || !claims.EmailVerified {
+			return ErrOIDCEmailNotVerified
+		}
+		user, err = repos.User.FindByEmail(ctx, claims.Email)
+		switch {
+		case err == nil:
+			// Akun yang emailnya belum diverifikasi bisa saja didaftarkan orang
+			// lain dengan email korban; jangan ditautkan
+			if user.EmailVerifiedAt == nil {
+				return ErrOIDCAccountUnverified
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			user, err = newOIDCUser(provider, claims, now)
+			if err != nil {
+				return err
+			}
+			if err := createUserWithStore(ctx, repos, user); err != nil {
+				return err
+			}
+			log.Printf("✅ user %d dibuat dari login OIDC %s", user.ID, provider)
+		default:
+			return err
+		}
+
+		return repos.Identity.Create(ctx, &models.UserIdentity{
+			IDUser:      user.ID,
+			Provider:    provider,
+			Subject:     claims.Subject,
+			Email:       truncate(claims.Email, 255),
+			LastLoginAt: &now,
+			CreatedAt:   now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// newOIDCUser: email sudah diverifikasi provider. Password diisi acak (bisa
+// diganti lewat lupa password) dan NoTelp berupa placeholder sampai user
+// mengisi nomornya sendiri.
+func newOIDCUser(provider string, claims *oidc.Claims, now time.Time) (*models.User, error) {
+	randomPassword, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+	nama := strings.TrimSpace(claims.Name)
+	if nama == "" {
+		nama = claims.Email[:strings.IndexByte(claims.Email+"@", '@')]
+	}
+	return &models.User{
+		Nama:            truncate(nama, 255),
+		Email:           claims.Email,
+		NoTelp:          models.PhonePlaceholderPrefix + utils.HashToken(provider + ":" + claims.Subject)[:32],
+		Password:        hashed,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/notify"
+	"FinalTask/internal/repository"
+	"FinalTask/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"` // bcrypt memakai 72 byte pertama
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+var (
+	ErrWrongPassword     = apperr.Unauthorized("password saat ini salah")
+	ErrPasswordTooLong   = apperr.InvalidField("new_password", "maksimal 72 byte")
+	ErrInvalidResetToken = apperr.BadRequest("token reset password tidak valid atau kedaluwarsa")
+	ErrSamePassword      = apperr.InvalidField("new_password", "password baru harus berbeda dari password lama")
+)
+
+func (s *authService) ChangePassword(ctx context.Context, userID uint, currentSessionID string, req ChangePasswordRequest) error {
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		user, err := repos.User.FindByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+			return ErrWrongPassword
+		}
+		if req.CurrentPassword == req.NewPassword {
+			return ErrSamePassword
+		}
+		if err := setPassword(ctx, repos, user, req.NewPassword); err != nil {
+			return err
+		}
+
+		// Sesi yang sedang dipakai tetap aktif, perangkat lain harus login ulang
+		now := time.Now()
+		sessions, err := repos.Session.ListActiveByUserID(ctx, userID, now)
+		if err != nil {
+			return err
+		}
+		for _, sess := range sessions {
+			if sess.ID == currentSessionID {
+				continue
+			}
+			if err := repos.Session.Revoke(ctx, sess.ID, now); err != nil {
+				return err
+			}
+			if err := repos.RefreshToken.RevokeFamily(ctx, sess.ID, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ForgotPassword mengirim link reset ke email user. Selalu sukses walau email
+// tidak terdaftar supaya endpoint tidak bisa dipakai menebak akun.
+func (s *authService) ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error {
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	plain, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	// Hanya token terbaru yang berlaku
+	err = s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		now := time.Now()
+		if err := repos.PasswordReset.InvalidateByUserID(ctx, user.ID, now); err != nil {
+			return err
+		}
+		return repos.PasswordReset.Create(ctx, &models.PasswordReset{
+			IDUser:    user.ID,
+			TokenHash: hash,
+			ExpiresAt: now.Add(s.authCfg.PasswordResetTTL),
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := notify.Message{
+		To:      user.Email,
+		Subject: "Reset password",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda.\n"+
+				"Buka link berikut dalam %s:\n\n%s\n\n"+
+				"Token: %s\n\nAbaikan email ini jika Anda tidak memintanya.\n",
+			user.Nama, s.authCfg.PasswordResetTTL, s.resetLink(plain), plain,
+		),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		// Tidak dikembalikan ke client agar respons sama untuk semua email
+		log.Printf("⚠️ gagal mengirim email reset password ke user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword memakai token sekali pakai lalu mencabut semua sesi user
+func (s *authService) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		reset, err := repos.PasswordReset.FindByHash(ctx, utils.HashToken(req.Token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+		now := time.Now()
+		if now.After(reset.ExpiresAt) {
+			return ErrInvalidResetToken
+		}
+		ok, err := repos.PasswordReset.MarkUsed(ctx, reset.ID, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidResetToken
+		}
+
+		user, err := repos.User.FindByID(ctx, reset.IDUser)
+		if err != nil {
+			return ErrInvalidResetToken
+		}
+		if err := setPassword(ctx, repos, user, req.NewPassword); err != nil {
+			return err
+		}
+		if err := repos.PasswordReset.InvalidateByUserID(ctx, user.ID, now); err != nil {
+			return err
+		}
+		// Token terbukti dari email pemilik akun, kunci login ikut dibuka
+		if err := repos.User.ResetLoginFailures(ctx, user.ID); err != nil {
+			return err
+		}
+
+		if err := repos.Session.RevokeByUserID(ctx, user.ID, now); err != nil {
+			return err
+		}
+		return repos.RefreshToken.RevokeByUserID(ctx, user.ID, now)
+	})
+}
+
+func (s *authService) resetLink(token string) string {
+	if s.authCfg.PasswordResetURL == "" {
+		return token
+	}
+	return s.authCfg.PasswordResetURL + "?token=" + url.QueryEscape(token)
+}
+
+func setPassword(ctx context.Context, repos *repository.Repositories, user *models.User, password string) error {
+	hashed, err := utils.HashPassword(password)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		// max=72 menghitung karakter, huruf multi-byte bisa melewati 72 byte
+		return ErrPasswordTooLong
+	}
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	user.UpdatedAt = time.Now()
+	return repos.User.Update(ctx, user)
+}
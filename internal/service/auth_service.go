@@ -3,26 +3,32 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
 	"time"
 
+	"FinalTask/config"
+	"FinalTask/internal/apperr"
 	"FinalTask/internal/models"
+	"FinalTask/internal/notify"
+	"FinalTask/internal/oidc"
 	"FinalTask/internal/repository"
 	"FinalTask/utils"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type RegisterRequest struct {
-	Nama         string `json:"nama"`
-	Email        string `json:"email"`
-	NoTelp       string `json:"no_telp"`
-	Password     string `json:"password"`
-	TanggalLahir string `json:"tanggal_lahir"` // format: YYYY-MM-DD
-	JenisKelamin string `json:"jenis_kelamin"`
+	Nama         string `json:"nama" validate:"required,max=255"`
+	Email        string `json:"email" validate:"required,email,max=255"`
+	NoTelp       string `json:"no_telp" validate:"required,phone"`
+	Password     string `json:"password" validate:"required,min=8,max=72"` // bcrypt memakai 72 byte pertama
+	TanggalLahir string `json:"tanggal_lahir" validate:"omitempty,date"`   // format: YYYY-MM-DD
+	JenisKelamin string `json:"jenis_kelamin" validate:"max=50"`
 	Tentang      string `json:"tentang"`
-	Pekerjaan    string `json:"pekerjaan"`
-	IDProvinsi   string `json:"id_provinsi"`
-	IDKota       string `json:"id_kota"`
+	Pekerjaan    string `json:"pekerjaan" validate:"max=255"`
+	IDProvinsi   string `json:"id_provinsi" validate:"omitempty,numeric"`
+	IDKota       string `json:"id_kota" validate:"omitempty,numeric"`
 }
 
 type LoginRequest struct {
@@ -30,20 +36,104 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// ClientInfo describes the device a session was opened from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// TokenPair is returned by Login and Refresh
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	ExpiresIn    int64  `json:"expires_in"` // detik sampai access token kedaluwarsa
+}
+
+var (
+	ErrInvalidRefreshToken = apperr.Unauthorized("refresh token tidak valid atau kedaluwarsa")
+	ErrRefreshTokenReused  = apperr.Unauthorized("refresh token sudah dipakai, sesi terkait dicabut")
+	ErrEmailTaken          = apperr.Conflict("email sudah terdaftar")
+	ErrAccountTaken        = apperr.Conflict("email atau nomor telepon sudah terdaftar")
+)
+
 type AuthService interface {
 	Register(ctx context.Context, req RegisterRequest) (*models.User, error)
-	Login(ctx context.Context, req LoginRequest) (string, error)
+	// Login returns tokens, or a 2FA challenge when a second factor is needed
+	Login(ctx context.Context, req LoginRequest, client ClientInfo) (*LoginResult, error)
+	// VerifyTwoFactor exchanges a challenge token plus code for real tokens
+	VerifyTwoFactor(ctx context.Context, req TwoFactorChallengeRequest, client ClientInfo) (*TwoFactorLoginResult, error)
+	// SetupTwoFactorChallenge starts TOTP setup for an admin forced to enrol at login
+	SetupTwoFactorChallenge(ctx context.Context, req TwoFactorSetupRequest) (*TOTPSetup, error)
+	// OIDC ("Login with ..."): daftar provider, URL login di provider dan
+	// callback yang menghasilkan token atau challenge 2FA seperti Login
+	OIDCProviders() []OIDCProviderInfo
+	OIDCAuthURL(ctx context.Context, provider string) (string, error)
+	OIDCCallback(ctx context.Context, provider string, req OIDCCallbackRequest, client ClientInfo) (*LoginResult, error)
+	Refresh(ctx context.Context, req RefreshRequest, client ClientInfo) (*TokenPair, error)
+	Logout(ctx context.Context, req RefreshRequest) error
+
+	// ChangePassword needs the current password; other sessions are logged out
+	ChangePassword(ctx context.Context, userID uint, currentSessionID string, req ChangePasswordRequest) error
+	ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req ResetPasswordRequest) error
+
+	// Admin: buka kunci akun & lihat audit login
+	UnlockAccount(ctx context.Context, userID uint) error
+	LoginHistory(ctx context.Context, userID uint, limit int) ([]LoginAuditEntry, error)
 }
 
 type authService struct {
-	userRepo  repository.UserRepository
-	storeRepo repository.StoreRepository
+	uow              repository.UnitOfWork
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	challengeRepo    repository.LoginChallengeRepository
+	settingRepo      repository.SettingRepository
+	oidcStateRepo    repository.OIDCStateRepository
+	oidcProviders    *oidc.Registry
+	verification     VerificationService
+	mailer           notify.Mailer
+	jwtKeys          *utils.JWTKeys
+	jwtCfg           config.JWTConfig
+	authCfg          config.AuthConfig
+	oidcCfg          config.OIDCConfig
 }
 
-func NewAuthService(userRepo repository.UserRepository, storeRepo repository.StoreRepository) AuthService {
+func NewAuthService(
+	uow repository.UnitOfWork,
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	challengeRepo repository.LoginChallengeRepository,
+	settingRepo repository.SettingRepository,
+	oidcStateRepo repository.OIDCStateRepository,
+	oidcProviders *oidc.Registry,
+	verification VerificationService,
+	mailer notify.Mailer,
+	jwtKeys *utils.JWTKeys,
+	jwtCfg config.JWTConfig,
+	authCfg config.AuthConfig,
+	oidcCfg config.OIDCConfig,
+) AuthService {
 	return &authService{
-		userRepo:  userRepo,
-		storeRepo: storeRepo,
+		uow:              uow,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		challengeRepo:    challengeRepo,
+		settingRepo:      settingRepo,
+		oidcStateRepo:    oidcStateRepo,
+		oidcProviders:    oidcProviders,
+		verification:     verification,
+		mailer:           mailer,
+		jwtKeys:          jwtKeys,
+		jwtCfg:           jwtCfg,
+		authCfg:          authCfg,
+		oidcCfg:          oidcCfg,
 	}
 }
 
@@ -54,7 +144,7 @@ func (s *authService) Register(ctx context.Context, req RegisterRequest) (*model
 		return nil, err // error DB lain
 	}
 	if err == nil && existingUser != nil {
-		return nil, errors.New("email sudah terdaftar")
+		return nil, ErrEmailTaken
 	}
 
 	// ====== Cek no_telp unik ======
@@ -63,7 +153,7 @@ func (s *authService) Register(ctx context.Context, req RegisterRequest) (*model
 		return nil, err // error DB lain
 	}
 	if err == nil && existingByPhone != nil {
-		return nil, errors.New("nomor telepon sudah terdaftar")
+		return nil, ErrPhoneTaken
 	}
 
 	// ====== Hash password ======
@@ -95,37 +185,268 @@ func (s *authService) Register(ctx context.Context, req RegisterRequest) (*model
 		UpdatedAt:    time.Now(),
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	// ====== Simpan user + auto-create store dalam satu transaksi ======
+	err = s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		return createUserWithStore(ctx, repos, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ====== Kirim kode verifikasi email & nomor telepon ======
+	// Gagal kirim tidak membatalkan registrasi, user bisa minta kirim ulang
+	for _, channel := range []string{models.VerificationEmail, models.VerificationPhone} {
+		if _, err := s.verification.Send(ctx, user.ID, channel); err != nil {
+			log.Printf("⚠️ gagal mengirim kode verifikasi %s untuk user %d: %v", channel, user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+func (s *authService) Login(ctx context.Context, req LoginRequest, client ClientInfo) (*LoginResult, error) {
+	now := time.Now()
+
+	// IP yang terlalu sering gagal ditolak sebelum query user & bcrypt
+	if err := s.checkIPLimit(ctx, client.IPAddress, now); err != nil {
+		s.recordAttempt(ctx, nil, req.Email, client, models.LoginIPBlocked)
+		return nil, err
+	}
+
+	// Cari user berdasarkan email
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		s.recordAttempt(ctx, nil, req.Email, client, models.LoginUnknownEmail)
+		return nil, ErrInvalidCredentials
+	}
+
+	// Akun terkunci / masih backoff: password tidak dicek sama sekali
+	if err := s.checkAccountLimit(ctx, user, now); err != nil {
+		var blocked *LoginBlockedError
+		if errors.As(err, &blocked) {
+			s.recordAttempt(ctx, &user.ID, req.Email, client, blocked.Reason)
+		}
+		return nil, err
+	}
+
+	// Cek password
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		s.recordAttempt(ctx, &user.ID, req.Email, client, models.LoginBadPassword)
+		if err := s.registerFailure(ctx, user, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.finishLogin(ctx, user, client)
+}
+
+// finishLogin dipanggil setelah faktor pertama lolos (password atau OIDC).
+// Bila masih perlu faktor kedua, token baru diberikan setelah challenge
+// dijawab di /auth/2fa/verify.
+func (s *authService) finishLogin(ctx context.Context, user *models.User, client ClientInfo) (*LoginResult, error) {
+	required, err := twoFactorRequired(ctx, s.settingRepo, user)
+	if err != nil {
+		return nil, err
+	}
+	if user.HasTwoFactor() || required {
+		purpose := models.ChallengeTOTP
+		if !user.HasTwoFactor() {
+			purpose = models.ChallengeEnroll
+		}
+		challenge, err := s.startChallenge(ctx, user, purpose)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{TwoFactorChallenge: challenge}, nil
+	}
+
+	var pair *TokenPair
+	err = s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		pair, err = s.openSession(ctx, repos, user, client)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.recordAttempt(ctx, &user.ID, user.Email, client, models.LoginOK)
+	return &LoginResult{TokenPair: pair}, nil
+}
 
-	// ====== Auto-create store ======
+// createUserWithStore menyimpan user baru sekaligus tokonya; setiap user
+// (lewat register maupun login OIDC) selalu punya tepat satu toko
+func createUserWithStore(ctx context.Context, repos *repository.Repositories, user *models.User) error {
+	if err := repos.User.Create(ctx, user); err != nil {
+		// Register paralel dengan email/nomor yang sama lolos cek awal
+		return apperr.FromDB(err, nil, ErrAccountTaken)
+	}
 	store := &models.Toko{
 		IDUser:    user.ID,
-		NamaToko:  req.Nama + "'s Store",
+		NamaToko:  user.Nama + "'s Store",
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	if err := s.storeRepo.Create(ctx, store); err != nil {
+	return repos.Store.Create(ctx, store)
+}
+
+// openSession membuka sesi baru untuk login yang sudah lolos semua cek;
+// ID sesi juga menjadi family refresh token
+func (s *authService) openSession(ctx context.Context, repos *repository.Repositories, user *models.User, client ClientInfo) (*TokenPair, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:         uuid.NewString(),
+		IDUser:     user.ID,
+		UserAgent:  truncate(client.UserAgent, 255),
+		IPAddress:  client.IPAddress,
+		ExpiresAt:  now.Add(s.jwtCfg.RefreshTTL),
+		LastSeenAt: now,
+		CreatedAt:  now,
+	}
+	if err := repos.Session.Create(ctx, session); err != nil {
 		return nil, err
 	}
+	if user.FailedLoginCount > 0 {
+		if err := repos.User.ResetLoginFailures(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.issueTokens(ctx, repos, user, session.ID)
+}
 
-	return user, nil
+// Refresh menukar refresh token dengan pasangan token baru (rotasi).
+// Token lama hanya bisa dipakai sekali; pemakaian ulang mencabut seluruh sesi.
+func (s *authService) Refresh(ctx context.Context, req RefreshRequest, client ClientInfo) (*TokenPair, error) {
+	var pair *TokenPair
+	var reused bool
+	var sessionID string
+
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		stored, err := repos.RefreshToken.FindByHash(ctx, utils.HashToken(req.RefreshToken))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		now := time.Now()
+
+		if stored.RevokedAt != nil {
+			// Sesi sudah logout/dicabut
+			return ErrInvalidRefreshToken
+		}
+		ok, err := repos.RefreshToken.MarkUsed(ctx, stored.ID, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// Sudah dirotasi/dicabut sebelumnya: kemungkinan token dicuri
+			reused = true
+			sessionID = stored.FamilyID
+			return ErrRefreshTokenReused
+		}
+		if now.After(stored.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		session, err := repos.Session.FindByID(ctx, stored.FamilyID)
+		if err != nil || session.RevokedAt != nil {
+			return ErrInvalidRefreshToken
+		}
+		session.LastSeenAt = now
+		session.ExpiresAt = now.Add(s.jwtCfg.RefreshTTL)
+		session.IPAddress = client.IPAddress
+		session.UserAgent = truncate(client.UserAgent, 255)
+		if err := repos.Session.Update(ctx, session); err != nil {
+			return err
+		}
+
+		user, err := repos.User.FindByID(ctx, stored.IDUser)
+		if err != nil {
+			return ErrInvalidRefreshToken
+		}
+		pair, err = s.issueTokens(ctx, repos, user, session.ID)
+		return err
+	})
+
+	// Pencabutan sesi dilakukan di luar tx di atas karena tx-nya di-rollback
+	if reused {
+		if err := s.revokeSession(ctx, sessionID); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
 }
 
-func (s *authService) Login(ctx context.Context, req LoginRequest) (string, error) {
-	// Cari user berdasarkan email
-	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+// Logout mencabut sesi (dan family refresh token) dari refresh token yang dikirim
+func (s *authService) Logout(ctx context.Context, req RefreshRequest) error {
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, utils.HashToken(req.RefreshToken))
 	if err != nil {
-		return "", errors.New("email atau password salah")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
 	}
+	return s.revokeSession(ctx, stored.FamilyID)
+}
 
-	// Cek password
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		return "", errors.New("email atau password salah")
+func (s *authService) revokeSession(ctx context.Context, sessionID string) error {
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		now := time.Now()
+		if err := repos.Session.Revoke(ctx, sessionID, now); err != nil {
+			return err
+		}
+		return repos.RefreshToken.RevokeFamily(ctx, sessionID, now)
+	})
+}
+
+// issueTokens membuat access token JWT dan refresh token baru untuk sesi yang sama.
+// Role & permission dibaca ulang setiap kali, jadi perubahan role berlaku
+// paling lambat saat refresh berikutnya.
+func (s *authService) issueTokens(ctx context.Context, repos *repository.Repositories, user *models.User, sessionID string) (*TokenPair, error) {
+	roles, err := repos.Role.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	roleNames, perms := flattenRoles(roles)
+	access, err := s.jwtKeys.GenerateJWT(utils.AccessClaims{
+		UserID:      user.ID,
+		SessionID:   sessionID,
+		Roles:       roleNames,
+		Permissions: perms,
+	})
+	if err != nil {
+		return nil, err
+	}
+	plain, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := repos.RefreshToken.Create(ctx, &models.RefreshToken{
+		IDUser:    user.ID,
+		FamilyID:  sessionID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.jwtCfg.RefreshTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
 	}
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: plain,
+		ExpiresIn:    int64(s.jwtCfg.AccessTTL.Seconds()),
+	}, nil
+}
 
-	// Buat JWT
-	token, err := utils.GenerateJWT(user.ID, user.IsAdmin)
-	return token, err
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
 }
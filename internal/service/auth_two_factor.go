@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+	"FinalTask/utils"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorChallenge is returned by Login instead of tokens when a second
+// factor is needed. EnrollmentRequired means 2FA is mandatory for the account
+// but not set up yet: call /auth/2fa/setup first.
+type TwoFactorChallenge struct {
+	TwoFactorRequired  bool   `json:"two_factor_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	ChallengeToken     string `json:"challenge_token"`
+	ChallengeExpiresIn int64  `json:"challenge_expires_in"` // detik
+}
+
+// LoginResult holds either the tokens or a 2FA challenge
+type LoginResult struct {
+	*TokenPair
+	*TwoFactorChallenge
+}
+
+// TwoFactorChallengeRequest answers a login challenge. Code is a TOTP code
+// or one of the recovery codes.
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=64"`
+}
+
+// TwoFactorSetupRequest starts the enrolment forced by an enroll challenge
+type TwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// TwoFactorLoginResult is the outcome of a solved challenge. RecoveryCodes
+// is only filled when the challenge completed a forced enrolment.
+type TwoFactorLoginResult struct {
+	*TokenPair
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+var ErrInvalidChallenge = apperr.Unauthorized("challenge login tidak valid atau kedaluwarsa, silakan login ulang")
+
+// startChallenge menyimpan hash challenge token; token asli hanya dikirim ke klien
+func (s *authService) startChallenge(ctx context.Context, user *models.User, purpose string) (*TwoFactorChallenge, error) {
+	plain, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.challengeRepo.Create(ctx, &models.LoginChallenge{
+		IDUser:    user.ID,
+		TokenHash: hash,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(s.authCfg.TwoFactorChallengeTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+	return &TwoFactorChallenge{
+		TwoFactorRequired:  true,
+		EnrollmentRequired: purpose == models.ChallengeEnroll,
+		ChallengeToken:     plain,
+		ChallengeExpiresIn: int64(s.authCfg.TwoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+// loadChallenge mengembalikan challenge yang masih bisa dipakai beserta usernya
+func (s *authService) loadChallenge(ctx context.Context, token string, now time.Time) (*models.LoginChallenge, *models.User, error) {
+	if token == "" {
+		return nil, nil, ErrInvalidChallenge
+	}
+	challenge, err := s.challengeRepo.FindByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, err
+	}
+	if challenge.UsedAt != nil || now.After(challenge.ExpiresAt) || challenge.Attempts >= s.authCfg.TwoFactorMaxAttempts {
+		return nil, nil, ErrInvalidChallenge
+	}
+	user, err := s.userRepo.FindByID(ctx, challenge.IDUser)
+	if err != nil {
+		return nil, nil, ErrInvalidChallenge
+	}
+	return challenge, user, nil
+}
+
+func (s *authService) SetupTwoFactorChallenge(ctx context.Context, req TwoFactorSetupRequest) (*TOTPSetup, error) {
+	challenge, user, err := s.loadChallenge(ctx, req.ChallengeToken, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Purpose != models.ChallengeEnroll {
+		return nil, ErrTwoFactorEnabled
+	}
+	return setupTOTP(ctx, s.userRepo, user, s.authCfg.TOTPIssuer)
+}
+
+func (s *authService) VerifyTwoFactor(ctx context.Context, req TwoFactorChallengeRequest, client ClientInfo) (*TwoFactorLoginResult, error) {
+	now := time.Now()
+	if err := s.checkIPLimit(ctx, client.IPAddress, now); err != nil {
+		return nil, err
+	}
+	challenge, user, err := s.loadChallenge(ctx, req.ChallengeToken, now)
+	if err != nil {
+		return nil, err
+	}
+	// Salah kode 2FA ikut menambah backoff/kunci akun seperti salah password
+	if err := s.checkAccountLimit(ctx, user, now); err != nil {
+		return nil, err
+	}
+	// Jatah percobaan diambil dengan update bersyarat sebelum kode diperiksa,
+	// jadi tebakan paralel tidak bisa melewati TwoFactorMaxAttempts. Di luar
+	// tx supaya tetap tersimpan walau tx di bawah di-rollback.
+	ok, err := s.challengeRepo.ClaimAttempt(ctx, challenge.ID, s.authCfg.TwoFactorMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidChallenge
+	}
+
+	result := &TwoFactorLoginResult{}
+	err = s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		var err error
+		switch challenge.Purpose {
+		case models.ChallengeEnroll:
+			result.RecoveryCodes, err = enableTOTP(ctx, repos, user, req.Code, now)
+		default:
+			err = checkSecondFactor(ctx, repos, user, req.Code, now)
+		}
+		if err != nil {
+			return err
+		}
+		used, err := repos.Challenge.MarkUsed(ctx, challenge.ID, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidChallenge
+		}
+		result.TokenPair, err = s.openSession(ctx, repos, user, client)
+		return err
+	})
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		s.recordAttempt(ctx, &user.ID, user.Email, client, models.LoginBad2FA)
+		if err := s.registerFailure(ctx, user, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.recordAttempt(ctx, &user.ID, user.Email, client, models.LoginOK)
+	return result, nil
+}
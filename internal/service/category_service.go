@@ -2,18 +2,18 @@ package service
 
 import (
 	"context"
-	"errors"
 
+	"FinalTask/internal/apperr"
 	"FinalTask/internal/models"
 	"FinalTask/internal/repository"
 )
 
 type CreateCategoryRequest struct {
-	NamaCategory string `json:"nama_category"`
+	NamaCategory string `json:"nama_category" validate:"required,max=255"`
 }
 
 type UpdateCategoryRequest struct {
-	NamaCategory string `json:"nama_category"`
+	NamaCategory string `json:"nama_category" validate:"required,max=255"`
 }
 
 type CategoryService interface {
@@ -24,6 +24,8 @@ type CategoryService interface {
 	GetByID(ctx context.Context, id uint) (*models.Category, error)
 }
 
+var ErrCategoryNotFound = apperr.NotFound("category not found")
+
 type categoryService struct {
 	repo repository.CategoryRepository
 }
@@ -35,7 +37,7 @@ func NewCategoryService(repo repository.CategoryRepository) CategoryService {
 func (s *categoryService) GetByID(ctx context.Context, id uint) (*models.Category, error) {
 	cat, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return nil, errors.New("category not found")
+		return nil, apperr.FromDB(err, ErrCategoryNotFound, nil)
 	}
 	return cat, nil
 }
@@ -55,7 +57,7 @@ func (s *categoryService) List(ctx context.Context) ([]*models.Category, error)
 func (s *categoryService) Update(ctx context.Context, id uint, req UpdateCategoryRequest) (*models.Category, error) {
 	cat, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return nil, errors.New("category not found")
+		return nil, apperr.FromDB(err, ErrCategoryNotFound, nil)
 	}
 	cat.NamaCategory = req.NamaCategory
 	if err := s.repo.Update(ctx, cat); err != nil {
@@ -66,7 +68,7 @@ func (s *categoryService) Update(ctx context.Context, id uint, req UpdateCategor
 
 func (s *categoryService) Delete(ctx context.Context, id uint) error {
 	if _, err := s.repo.FindByID(ctx, id); err != nil {
-		return errors.New("category not found")
+		return apperr.FromDB(err, ErrCategoryNotFound, nil)
 	}
 	return s.repo.Delete(ctx, id)
 }
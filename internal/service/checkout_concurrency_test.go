@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+	"FinalTask/internal/service"
+)
+
+const (
+	checkoutBuyers = 40 // checkout paralel
+	checkoutStock  = 10 // stok awal, lebih kecil dari jumlah pembeli
+)
+
+// TestCheckoutNoOversell runs checkoutBuyers checkouts at once, each buying
+// one unit of a product with checkoutStock units. Exactly checkoutStock must
+// succeed, every other one must get 409 ErrOutOfStock, and stok must end at
+// 0, never below.
+//
+// The conditional decrement only proves itself on a server database, where
+// every checkout runs on its own connection and the UPDATEs wait on the row
+// lock; see openTestDB for TEST_DB_DRIVER/TEST_DB_DSN. On the SQLite fallback
+// the run checks the accounting (successes, 409s, final stok) only.
+func TestCheckoutNoOversell(t *testing.T) {
+	ctx := context.Background()
+	parallel(t)
+	db := openTestDB(t, checkoutBuyers)
+	repos := repository.NewRepositories(db)
+	prod, buyers := seedCheckout(t, ctx, repos)
+
+	trxService := service.NewTransactionService(repository.NewUnitOfWork(db), repos.Transaction, repos.User, repos.Store)
+	start := make(chan struct{})
+	errs := make([]error, len(buyers))
+	var wg sync.WaitGroup
+	for i, b := range buyers {
+		wg.Add(1)
+		go func(i int, b checkoutBuyer) {
+			defer wg.Done()
+			<-start // semua goroutine mulai bersamaan
+			_, errs[i] = trxService.Create(ctx, b.userID, service.CreateTransactionRequest{
+				AlamatPengiriman: b.alamatID,
+				MethodBayar:      "transfer",
+				Items:            []service.CheckoutItem{{IDProduk: prod.ID, Kuantitas: 1}},
+			})
+		}(i, b)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded, outOfStock := 0, 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, service.ErrOutOfStock) && apperr.CodeOf(err).Status() == http.StatusConflict:
+			outOfStock++
+		default:
+			t.Errorf("buyer %d: unexpected error: %v", i, err)
+		}
+	}
+	if succeeded != checkoutStock {
+		t.Errorf("successful checkouts = %d, want %d", succeeded, checkoutStock)
+	}
+	if outOfStock != checkoutBuyers-checkoutStock {
+		t.Errorf("409 out of stock = %d, want %d", outOfStock, checkoutBuyers-checkoutStock)
+	}
+
+	after, err := repos.Product.FindByID(ctx, prod.ID)
+	if err != nil {
+		t.Fatalf("reload product: %v", err)
+	}
+	if after.Stok != 0 {
+		t.Errorf("final stok = %d, want 0", after.Stok)
+	}
+
+	// Setiap checkout yang sukses tercatat tepat satu baris, yang gagal tidak sama sekali
+	var sold int64
+	if err := db.Model(&models.DetailTrx{}).
+		Where("id_log_produk = ?", *prod.IDLogProduk).
+		Select("COALESCE(SUM(kuantitas), 0)").
+		Scan(&sold).Error; err != nil {
+		t.Fatalf("sum detail_trxes: %v", err)
+	}
+	if sold != checkoutStock {
+		t.Errorf("units in detail_trxes = %d, want %d", sold, checkoutStock)
+	}
+}
+
+type checkoutBuyer struct {
+	userID   uint
+	alamatID uint
+}
+
+// seedCheckout creates a store with one product of checkoutStock units and
+// checkoutBuyers verified buyers with an address each. Emails and slugs carry
+// a run suffix so the test can be repeated on a shared server database.
+func seedCheckout(t *testing.T, ctx context.Context, repos *repository.Repositories) (*models.Produk, []checkoutBuyer) {
+	t.Helper()
+	run := time.Now().UnixNano()
+	now := time.Now()
+
+	newUser := func(name string, n int) *models.User {
+		user := &models.User{
+			Nama:            name,
+			Email:           fmt.Sprintf("%s-%d@example.com", name, run),
+			NoTelp:          fmt.Sprintf("08%d%03d", run%1e9, n),
+			Password:        "-",
+			EmailVerifiedAt: &now,
+			PhoneVerifiedAt: &now,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		if err := repos.User.Create(ctx, user); err != nil {
+			t.Fatalf("create user %s: %v", name, err)
+		}
+		return user
+	}
+
+	seller := newUser("seller", 0)
+	store := &models.Toko{IDUser: seller.ID, NamaToko: "Toko Uji", CreatedAt: now, UpdatedAt: now}
+	if err := repos.Store.Create(ctx, store); err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	category := &models.Category{NamaCategory: "Uji", CreatedAt: now, UpdatedAt: now}
+	if err := repos.Category.Create(ctx, category); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	prod := &models.Produk{
+		NamaProduk:    "Produk Uji",
+		Slug:          fmt.Sprintf("produk-uji-%d", run),
+		HargaReseller: 8000,
+		HargaKonsumen: 10000,
+		Stok:          checkoutStock,
+		Status:        models.ProdukPublished,
+		IDToko:        store.ID,
+		IDCategory:    category.ID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := repos.Product.Create(ctx, prod); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	logEntry := &models.LogProduk{
+		IDProduk:      prod.ID,
+		Versi:         1,
+		NamaProduk:    prod.NamaProduk,
+		Slug:          prod.Slug,
+		HargaReseller: prod.HargaReseller,
+		HargaKonsumen: prod.HargaKonsumen,
+		IDToko:        prod.IDToko,
+		IDCategory:    prod.IDCategory,
+		StokAwal:      prod.Stok,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := repos.Product.CreateLog(ctx, logEntry); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if err := repos.Product.SetCurrentLog(ctx, prod.ID, logEntry.ID); err != nil {
+		t.Fatalf("set current snapshot: %v", err)
+	}
+	prod.IDLogProduk = &logEntry.ID
+
+	buyers := make([]checkoutBuyer, checkoutBuyers)
+	for i := range buyers {
+		user := newUser(fmt.Sprintf("buyer%d", i), i+1)
+		addr := &models.Alamat{IDUser: user.ID, JudulAlamat: "Rumah", NamaPenerima: user.Nama, CreatedAt: now, UpdatedAt: now}
+		if err := repos.Address.Create(ctx, addr); err != nil {
+			t.Fatalf("create address: %v", err)
+		}
+		buyers[i] = checkoutBuyer{userID: user.ID, alamatID: addr.ID}
+	}
+	return prod, buyers
+}
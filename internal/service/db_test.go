@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"FinalTask/config"
+	"FinalTask/internal/migrations"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to TEST_DB_DRIVER/TEST_DB_DSN, or a temporary SQLite
+// file, with a pool of conns connections and runs every migration. Use an
+// empty server database, e.g.:
+//
+//	TEST_DB_DRIVER=postgres TEST_DB_DSN="host=localhost user=app password=secret dbname=finaltask_test sslmode=disable" go test ./internal/service
+//	TEST_DB_DRIVER=mysql TEST_DB_DSN="app:secret@tcp(localhost:3306)/finaltask_test?parseTime=True" go test ./internal/service
+//
+// Tests add rows with a per-run suffix, so a server database can be reused.
+// SQLite lets only one writer in at a time: concurrency tests still run
+// there but do not exercise row locking.
+func openTestDB(t *testing.T, conns int) *gorm.DB {
+	t.Helper()
+	driver, dsn := os.Getenv("TEST_DB_DRIVER"), os.Getenv("TEST_DB_DSN")
+
+	var db *gorm.DB
+	var err error
+	if dsn == "" {
+		t.Log("TEST_DB_DSN kosong: memakai SQLite, penulisan tetap berurutan")
+		// config.OpenDB membatasi SQLite ke satu koneksi, di sini setiap goroutine
+		// mendapat koneksi sendiri. _txlock=immediate mencegah SQLITE_BUSY saat
+		// transaksi baca naik menjadi transaksi tulis.
+		path := filepath.Join(t.TempDir(), "test.db")
+		db, err = gorm.Open(sqlite.Open(path+"?_journal_mode=WAL&_busy_timeout=30000&_txlock=immediate&_foreign_keys=on"),
+			&gorm.Config{TranslateError: true})
+	} else {
+		db, err = config.OpenDB(config.DBConfig{Driver: driver, DSN: dsn, MaxOpenConns: conns, MaxIdleConns: conns})
+	}
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(conns)
+	sqlDB.SetMaxIdleConns(conns)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	warmPool(t, sqlDB, conns)
+	return db
+}
+
+// parallel raises GOMAXPROCS for the test so goroutines that race on the
+// database really interleave, also on a single-CPU CI runner
+func parallel(t *testing.T) {
+	prev := runtime.GOMAXPROCS(0)
+	if prev < 8 {
+		runtime.GOMAXPROCS(8)
+		t.Cleanup(func() { runtime.GOMAXPROCS(prev) })
+	}
+}
+
+// warmPool opens all conns connections up front. Otherwise goroutines that
+// start together still reach the database one by one while new connections
+// are dialed, and a race in the code under test never shows up.
+func warmPool(t *testing.T, sqlDB *sql.DB, conns int) {
+	t.Helper()
+	ctx := context.Background()
+	held := make([]*sql.Conn, 0, conns)
+	for i := 0; i < conns; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			t.Fatalf("open connection: %v", err)
+		}
+		if err := conn.PingContext(ctx); err != nil {
+			t.Fatalf("ping connection: %v", err)
+		}
+		held = append(held, conn)
+	}
+	for _, conn := range held {
+		conn.Close()
+	}
+}
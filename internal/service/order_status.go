@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+)
+
+// OrderActor is the user asking for a status change. Staff means the token
+// carries order:manage; the buyer and seller roles follow from the order.
+// StoreID is set for store API keys, which can only act as that seller.
+type OrderActor struct {
+	UserID  uint
+	Staff   bool
+	StoreID uint
+}
+
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=paid processing shipped delivered completed cancelled refunded"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+// CancelOrderRequest is the body of the buyer cancel and seller reject routes
+type CancelOrderRequest struct {
+	Alasan string `json:"alasan" validate:"required,max=255"`
+}
+
+var (
+	ErrInvalidTransition = apperr.Conflict("perubahan status pesanan tidak diizinkan")
+	ErrStatusNotAllowed  = apperr.Forbidden("Anda tidak berhak mengubah pesanan ke status ini")
+	ErrOrderChanged      = apperr.Conflict("status pesanan baru saja berubah, muat ulang lalu coba lagi")
+	ErrNothingToRefund   = apperr.Conflict("pesanan dibatalkan sebelum dibayar, tidak ada dana untuk dikembalikan")
+)
+
+// orderStatusActors: siapa yang boleh memindahkan pesanan ke status tsb.
+// Belum ada payment gateway, jadi pembayaran dikonfirmasi staff.
+var orderStatusActors = map[string][]string{
+	models.OrderPaid:       {models.ActorStaff},
+	models.OrderProcessing: {models.ActorSeller, models.ActorStaff},
+	models.OrderShipped:    {models.ActorSeller, models.ActorStaff},
+	models.OrderDelivered:  {models.ActorSeller, models.ActorStaff},
+	models.OrderCompleted:  {models.ActorBuyer, models.ActorStaff},
+	models.OrderCancelled:  {models.ActorStaff}, // buyer/seller lewat Cancel/Reject
+	models.OrderRefunded:   {models.ActorStaff},
+}
+
+// Cancel dipakai pembeli (atau staff), Reject dipakai penjual (atau staff)
+var (
+	cancelActors = []string{models.ActorBuyer, models.ActorStaff}
+	rejectActors = []string{models.ActorSeller, models.ActorStaff}
+)
+
+func (s *transactionService) UpdateStatus(ctx context.Context, actor OrderActor, id uint, req UpdateOrderStatusRequest) (*models.Trx, error) {
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		trx, role, err := authorizeOrder(ctx, repos, actor, id, orderStatusActors[req.Status])
+		if err != nil {
+			return err
+		}
+		switch req.Status {
+		case models.OrderCancelled:
+			reason := req.Note
+			if reason == "" {
+				reason = "dibatalkan staff"
+			}
+			return cancelOrder(ctx, repos, trx, role, &actor.UserID, reason)
+		case models.OrderRefunded:
+			return refundOrder(ctx, repos, trx, role, &actor.UserID, req.Note)
+		}
+		return transitionOrder(ctx, repos, trx, req.Status, role, &actor.UserID, req.Note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.trxRepo.FindAnyByID(ctx, id)
+}
+
+// Cancel: pembeli membatalkan pesanannya sebelum dikirim
+func (s *transactionService) Cancel(ctx context.Context, actor OrderActor, id uint, req CancelOrderRequest) (*models.Trx, error) {
+	return s.cancel(ctx, actor, id, cancelActors, req.Alasan)
+}
+
+// Reject: penjual menolak pesanan sebelum dikirim
+func (s *transactionService) Reject(ctx context.Context, actor OrderActor, id uint, req CancelOrderRequest) (*models.Trx, error) {
+	return s.cancel(ctx, actor, id, rejectActors, req.Alasan)
+}
+
+func (s *transactionService) cancel(ctx context.Context, actor OrderActor, id uint, allowed []string, reason string) (*models.Trx, error) {
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		trx, role, err := authorizeOrder(ctx, repos, actor, id, allowed)
+		if err != nil {
+			return err
+		}
+		return cancelOrder(ctx, repos, trx, role, &actor.UserID, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.trxRepo.FindAnyByID(ctx, id)
+}
+
+// authorizeOrder memuat pesanan dan memilih peran actor yang termasuk
+// allowed. Pesanan orang lain tetap dilaporkan "not found".
+func authorizeOrder(ctx context.Context, repos *repository.Repositories, actor OrderActor, id uint, allowed []string) (*models.Trx, string, error) {
+	trx, err := repos.Transaction.FindAnyByID(ctx, id)
+	if err != nil {
+		return nil, "", apperr.FromDB(err, ErrTransactionNotFound, nil)
+	}
+	roles, err := actorRoles(ctx, repos, actor, trx)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(roles) == 0 {
+		return nil, "", ErrTransactionNotFound
+	}
+	role := pickRole(roles, allowed)
+	if role == "" {
+		return nil, "", ErrStatusNotAllowed
+	}
+	return trx, role, nil
+}
+
+// cancelOrder membatalkan pesanan yang belum dikirim: status, stok setiap
+// DetailTrx dan refund (bila sudah dibayar) berubah di tx yang sama, jadi
+// pembatalan ganda tidak mungkin mengembalikan stok dua kali
+func cancelOrder(ctx context.Context, repos *repository.Repositories, trx *models.Trx, role string, actorID *uint, reason string) error {
+	wasPaid := trx.Status == models.OrderPaid || trx.Status == models.OrderProcessing
+	if err := transitionOrder(ctx, repos, trx, models.OrderCancelled, role, actorID, reason); err != nil {
+		return err
+	}
+	for _, d := range trx.DetailTrx {
+		logEntry, err := repos.Product.FindLogByID(ctx, d.IDLogProduk)
+		if err != nil {
+			return err
+		}
+		if err := repos.Product.RestoreStock(ctx, logEntry.IDProduk, d.Kuantitas); err != nil {
+			return err
+		}
+	}
+	if !wasPaid {
+		return nil
+	}
+	now := time.Now()
+	return repos.Transaction.CreateRefund(ctx, &models.Refund{
+		IDTrx:     trx.ID,
+		Amount:    trx.HargaTotal,
+		Status:    models.RefundPending,
+		Reason:    reason,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// refundOrder menandai dana sudah dikembalikan: refund dari pembatalan
+// diselesaikan, retur setelah delivered langsung dibuat selesai
+func refundOrder(ctx context.Context, repos *repository.Repositories, trx *models.Trx, role string, actorID *uint, note string) error {
+	refund, err := repos.Transaction.FindRefund(ctx, trx.ID)
+	if err != nil && !apperr.IsNotFound(err) {
+		return err
+	}
+	// Pesanan yang dibatalkan sebelum dibayar tidak punya refund
+	if refund == nil && trx.Status == models.OrderCancelled {
+		return ErrNothingToRefund
+	}
+	if err := transitionOrder(ctx, repos, trx, models.OrderRefunded, role, actorID, note); err != nil {
+		return err
+	}
+	now := time.Now()
+	if refund == nil {
+		return repos.Transaction.CreateRefund(ctx, &models.Refund{
+			IDTrx:       trx.ID,
+			Amount:      trx.HargaTotal,
+			Status:      models.RefundCompleted,
+			Reason:      note,
+			CompletedAt: &now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+	refund.Status = models.RefundCompleted
+	refund.CompletedAt = &now
+	refund.UpdatedAt = now
+	return repos.Transaction.UpdateRefund(ctx, refund)
+}
+
+// transitionOrder menjalankan satu perubahan status sesuai state machine dan
+// mencatatnya di order_events. Harus dipanggil di dalam tx.
+func transitionOrder(ctx context.Context, repos *repository.Repositories, trx *models.Trx, to, role string, actorID *uint, note string) error {
+	if !models.CanTransition(trx.Status, to) {
+		allowed := models.NextOrderStatuses(trx.Status)
+		if allowed == nil {
+			allowed = []string{}
+		}
+		return ErrInvalidTransition.WithDetails(map[string]interface{}{
+			"from":    trx.Status,
+			"to":      to,
+			"allowed": allowed,
+		})
+	}
+	ok, err := repos.Transaction.UpdateStatus(ctx, trx.ID, trx.Status, to)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOrderChanged
+	}
+	event := &models.OrderEvent{
+		IDTrx:      trx.ID,
+		FromStatus: trx.Status,
+		ToStatus:   to,
+		IDActor:    actorID,
+		ActorRole:  role,
+		Note:       note,
+		CreatedAt:  time.Now(),
+	}
+	trx.Status = to
+	return repos.Transaction.CreateEvent(ctx, event)
+}
+
+// actorRoles returns every role actor holds on trx. Seller means every line
+// of the order belongs to the actor's store; checkout splits carts per store,
+// only orders from before that can mix stores (staff only).
+func actorRoles(ctx context.Context, repos *repository.Repositories, actor OrderActor, trx *models.Trx) ([]string, error) {
+	var roles []string
+	if actor.StoreID != 0 {
+		if sellsWholeOrder(actor.StoreID, trx) {
+			roles = append(roles, models.ActorSeller)
+		}
+		return roles, nil
+	}
+	if trx.IDUser == actor.UserID {
+		roles = append(roles, models.ActorBuyer)
+	}
+	store, err := repos.Store.FindByUserID(ctx, actor.UserID)
+	switch {
+	case err == nil:
+		if sellsWholeOrder(store.ID, trx) {
+			roles = append(roles, models.ActorSeller)
+		}
+	case !apperr.IsNotFound(err):
+		return nil, err
+	}
+	if actor.Staff {
+		roles = append(roles, models.ActorStaff)
+	}
+	return roles, nil
+}
+
+func sellsWholeOrder(storeID uint, trx *models.Trx) bool {
+	if len(trx.DetailTrx) == 0 {
+		return false
+	}
+	for _, d := range trx.DetailTrx {
+		if d.IDToko != storeID {
+			return false
+		}
+	}
+	return true
+}
+
+// pickRole returns the first allowed role the actor holds, "" if none
+func pickRole(roles, allowed []string) string {
+	for _, a := range allowed {
+		for _, r := range roles {
+			if r == a {
+				return r
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"FinalTask/internal/apperr"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+	maxPage         = 1 << 20 // offset tetap jauh dari overflow
+)
+
+var (
+	ErrInvalidPage  = apperr.InvalidField("page", "page harus angka bulat lebih dari 0")
+	ErrInvalidLimit = apperr.InvalidField("limit", fmt.Sprintf("limit harus angka bulat lebih dari 0 (maks %d)", maxPageSize))
+)
+
+// parsePage reads ?page= and ?limit= (default 1 and defaultPageSize) into an
+// offset and limit. Non-numeric or non-positive values are rejected and a
+// limit above maxPageSize is capped to it.
+func parsePage(qs map[string]string) (offset, limit int, err error) {
+	page, limit := 1, defaultPageSize
+	if v := qs["page"]; v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 || page > maxPage {
+			return 0, 0, ErrInvalidPage
+		}
+	}
+	if v := qs["limit"]; v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return 0, 0, ErrInvalidLimit
+		}
+		limit = min(limit, maxPageSize)
+	}
+	return (page - 1) * limit, limit, nil
+}
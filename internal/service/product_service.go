@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -11,56 +10,91 @@ import (
 	"strconv"
 	"time"
 
+	"FinalTask/internal/apperr"
 	"FinalTask/internal/models"
 	"FinalTask/internal/repository"
 )
 
 type CreateProductRequest struct {
-	NamaProduk    string `json:"nama_produk"`
-	Slug          string `json:"slug"`
-	HargaReseller string `json:"harga_reseller"`
-	HargaKonsumen string `json:"harga_konsumen"`
-	Stok          int    `json:"stok"`
-	Deskripsi     string `json:"deskripsi"`
-	IDCategory    uint   `json:"id_category"`
+	NamaProduk string `json:"nama_produk" validate:"required,max=255"`
+	Slug       string `json:"slug" validate:"omitempty,max=255"`
+	// Harga dalam rupiah utuh sebagai angka JSON (150000)
+	HargaReseller models.Money `json:"harga_reseller" validate:"min=1,max=1000000000000"`
+	HargaKonsumen models.Money `json:"harga_konsumen" validate:"min=1,max=1000000000000"`
+	Stok          int          `json:"stok" validate:"min=0"`
+	Deskripsi     string       `json:"deskripsi"`
+	IDCategory    uint         `json:"id_category" validate:"required"`
+	// Kosong: published saat dibuat, tidak berubah saat update
+	Status string `json:"status" validate:"omitempty,oneof=published draft"`
+}
+
+var (
+	ErrProductNotFound = apperr.NotFound("product not found")
+	ErrNotProductOwner = apperr.Forbidden("produk bukan milik toko Anda")
+	ErrSlugTaken       = apperr.Conflict("slug produk sudah dipakai")
+	ErrUnknownCategory = apperr.InvalidField("id_category", "category not found")
+	// Dua perubahan bersamaan berebut nomor versi yang sama
+	ErrProductChanged = apperr.Conflict("produk sedang diubah bersamaan, silakan ulangi")
+)
+
+// ProductViewer is the caller of a product read. Draft products are only
+// visible to the owning store and to staff (store:read).
+type ProductViewer struct {
+	UserID uint
+	Staff  bool
 }
 
 type ProductService interface {
 	Create(ctx context.Context, userID uint, req CreateProductRequest) (*models.Produk, error)
 	List(ctx context.Context, qs map[string]string) ([]*models.Produk, error)
-	GetByID(ctx context.Context, id uint) (*models.Produk, error)
+	GetByID(ctx context.Context, viewer ProductViewer, id uint) (*models.Produk, error)
 	Update(ctx context.Context, userID, id uint, req CreateProductRequest) (*models.Produk, error)
 	Delete(ctx context.Context, userID, id uint) error
-	UploadImage(ctx context.Context, id uint, file *multipart.FileHeader) (string, error)
+	// ListVersions returns the product and its snapshots, newest first
+	ListVersions(ctx context.Context, viewer ProductViewer, id uint) (*models.Produk, []*models.LogProduk, error)
+	UploadImage(ctx context.Context, userID, id uint, file *multipart.FileHeader) (string, error)
 }
 
 type productService struct {
+	uow          repository.UnitOfWork
 	repo         repository.ProductRepository
 	storeRepo    repository.StoreRepository
 	categoryRepo repository.CategoryRepository
+	userRepo     repository.UserRepository
+	uploadDir    string
 }
 
 func NewProductService(
+	uow repository.UnitOfWork,
 	pr repository.ProductRepository,
 	sr repository.StoreRepository,
 	cr repository.CategoryRepository,
+	ur repository.UserRepository,
+	uploadDir string,
 ) ProductService {
 	return &productService{
+		uow:          uow,
 		repo:         pr,
 		storeRepo:    sr,
 		categoryRepo: cr,
+		userRepo:     ur,
+		uploadDir:    uploadDir,
 	}
 }
 
 func (s *productService) Create(ctx context.Context, userID uint, req CreateProductRequest) (*models.Produk, error) {
+	// 0. Hanya akun terverifikasi yang boleh berjualan
+	if err := ensureVerified(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
 	// 1. Validasi toko user
 	store, err := s.storeRepo.FindByUserID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("store not found for user")
+		return nil, apperr.FromDB(err, ErrStoreNotFound, nil)
 	}
 	// 2. Validasi kategori
 	if _, err := s.categoryRepo.FindByID(ctx, req.IDCategory); err != nil {
-		return nil, errors.New("category not found")
+		return nil, apperr.FromDB(err, ErrUnknownCategory, nil)
 	}
 	// 3. Generate slug jika kosong
 	slug := req.Slug
@@ -68,6 +102,10 @@ func (s *productService) Create(ctx context.Context, userID uint, req CreateProd
 		slug = fmt.Sprintf("%s-%d", req.NamaProduk, time.Now().Unix())
 	}
 	// 4. Buat produk master
+	status := req.Status
+	if status == "" {
+		status = models.ProdukPublished
+	}
 	prod := &models.Produk{
 		NamaProduk:    req.NamaProduk,
 		Slug:          slug,
@@ -77,15 +115,34 @@ func (s *productService) Create(ctx context.Context, userID uint, req CreateProd
 		Deskripsi:     req.Deskripsi,
 		IDToko:        store.ID,
 		IDCategory:    req.IDCategory,
+		Status:        status,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
-	if err := s.repo.Create(ctx, prod); err != nil {
+	// 5. Simpan produk + snapshot versi pertama secara atomik
+	err = s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Product.Create(ctx, prod); err != nil {
+			return apperr.FromDB(err, nil, ErrSlugTaken)
+		}
+		return snapshotProduct(ctx, repos, prod)
+	})
+	if err != nil {
 		return nil, err
 	}
-	// 5. Buat initial log_produk snapshot
+	return prod, nil
+}
+
+// snapshotProduct menambah versi LogProduk dari data produk saat ini dan
+// menandainya sebagai versi terkini yang dipakai checkout
+func snapshotProduct(ctx context.Context, repos *repository.Repositories, prod *models.Produk) error {
+	versi, err := repos.Product.LatestLogVersion(ctx, prod.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
 	log := &models.LogProduk{
 		IDProduk:      prod.ID,
+		Versi:         versi + 1,
 		NamaProduk:    prod.NamaProduk,
 		Slug:          prod.Slug,
 		HargaReseller: prod.HargaReseller,
@@ -94,55 +151,62 @@ func (s *productService) Create(ctx context.Context, userID uint, req CreateProd
 		IDToko:        prod.IDToko,
 		IDCategory:    prod.IDCategory,
 		StokAwal:      prod.Stok,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
-	if err := s.repo.CreateLog(ctx, log); err != nil {
-		return nil, err
+	if err := repos.Product.CreateLog(ctx, log); err != nil {
+		return apperr.FromDB(err, nil, ErrProductChanged)
 	}
-	return prod, nil
+	prod.IDLogProduk = &log.ID
+	return repos.Product.SetCurrentLog(ctx, prod.ID, log.ID)
 }
 
 func (s *productService) List(ctx context.Context, qs map[string]string) ([]*models.Produk, error) {
-	page, limit := 1, 10
-	var category uint
-	if v, ok := qs["page"]; ok {
-		if p, err := strconv.Atoi(v); err == nil {
-			page = p
+	offset, limit, err := parsePage(qs)
+	if err != nil {
+		return nil, err
+	}
+	// Katalog hanya berisi produk published (draft & yang dihapus tidak)
+	filter := repository.ProductFilter{Status: models.ProdukPublished}
+	if v, ok := qs["id_category"]; ok {
+		if c, err := strconv.Atoi(v); err == nil {
+			filter.CategoryID = uint(c)
 		}
 	}
-	if v, ok := qs["limit"]; ok {
-		if l, err := strconv.Atoi(v); err == nil {
-			limit = l
+	// Filter & urutan harga berjalan di SQL sejak harga disimpan sebagai angka
+	if v, ok := qs["min_harga"]; ok {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			filter.MinHarga = models.Money(n)
 		}
 	}
-	if v, ok := qs["id_category"]; ok {
-		if c, err := strconv.Atoi(v); err == nil {
-			category = uint(c)
+	if v, ok := qs["max_harga"]; ok {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			filter.MaxHarga = models.Money(n)
 		}
 	}
-	return s.repo.List(ctx, (page-1)*limit, limit, category)
+	filter.Sort = qs["sort"]
+	return s.repo.List(ctx, offset, limit, filter)
 }
 
-func (s *productService) GetByID(ctx context.Context, id uint) (*models.Produk, error) {
-	return s.repo.FindByID(ctx, id)
+func (s *productService) GetByID(ctx context.Context, viewer ProductViewer, id uint) (*models.Produk, error) {
+	return s.findVisible(ctx, viewer, id)
 }
 
 func (s *productService) Update(ctx context.Context, userID, id uint, req CreateProductRequest) (*models.Produk, error) {
 	// 1. Ambil dan periksa produk
-	prod, err := s.repo.FindByID(ctx, id)
+	prod, err := s.findOwned(ctx, userID, id)
 	if err != nil {
-		return nil, errors.New("product not found")
+		return nil, err
 	}
-	store, err := s.storeRepo.FindByUserID(ctx, userID)
-	if err != nil || prod.IDToko != store.ID {
-		return nil, errors.New("unauthorized")
+	if err := ensureVerified(ctx, s.userRepo, userID); err != nil {
+		return nil, err
 	}
 	// 2. Validasi kategori
 	if _, err := s.categoryRepo.FindByID(ctx, req.IDCategory); err != nil {
-		return nil, errors.New("category not found")
+		return nil, apperr.FromDB(err, ErrUnknownCategory, nil)
 	}
 	// 3. Terapkan perubahan
+	before := *prod
 	prod.NamaProduk = req.NamaProduk
 	if req.Slug != "" {
 		prod.Slug = req.Slug
@@ -152,32 +216,109 @@ func (s *productService) Update(ctx context.Context, userID, id uint, req Create
 	prod.Stok = req.Stok
 	prod.Deskripsi = req.Deskripsi
 	prod.IDCategory = req.IDCategory
+	if req.Status != "" {
+		prod.Status = req.Status
+	}
 	prod.UpdatedAt = time.Now()
-	if err := s.repo.Update(ctx, prod); err != nil {
+	// 4. Simpan; perubahan data yang tercatat di snapshot menambah versi baru
+	// (perubahan stok saja tidak)
+	err = s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Product.Update(ctx, prod); err != nil {
+			return apperr.FromDB(err, nil, ErrSlugTaken)
+		}
+		if !snapshotChanged(&before, prod) {
+			return nil
+		}
+		return snapshotProduct(ctx, repos, prod)
+	})
+	if err != nil {
 		return nil, err
 	}
+	// Muat ulang agar kategori di respons mengikuti id_category yang baru
+	prod, err = s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrProductNotFound, nil)
+	}
 	return prod, nil
 }
 
+// snapshotChanged reports whether a field copied into LogProduk differs
+func snapshotChanged(old, cur *models.Produk) bool {
+	return old.NamaProduk != cur.NamaProduk ||
+		old.Slug != cur.Slug ||
+		old.HargaReseller != cur.HargaReseller ||
+		old.HargaKonsumen != cur.HargaKonsumen ||
+		old.Deskripsi != cur.Deskripsi ||
+		old.IDCategory != cur.IDCategory ||
+		old.IDLogProduk == nil
+}
+
+func (s *productService) ListVersions(ctx context.Context, viewer ProductViewer, id uint) (*models.Produk, []*models.LogProduk, error) {
+	prod, err := s.findVisible(ctx, viewer, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	logs, err := s.repo.ListLogs(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prod, logs, nil
+}
+
 func (s *productService) Delete(ctx context.Context, userID, id uint) error {
-	// 1. Ambil produk
+	// 1. Ambil produk & cek kepemilikan
+	if _, err := s.findOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	// 2. Hapus produk
+	return s.repo.Delete(ctx, id)
+}
+
+// findOwned memuat produk dan memastikan produk itu milik toko user
+// findVisible loads a product the viewer may read. Drafts of other stores
+// are reported as not found, like missing products.
+func (s *productService) findVisible(ctx context.Context, viewer ProductViewer, id uint) (*models.Produk, error) {
 	prod, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return errors.New("product not found")
+		return nil, apperr.FromDB(err, ErrProductNotFound, nil)
+	}
+	if prod.Status == models.ProdukPublished || viewer.Staff {
+		return prod, nil
+	}
+	store, err := s.storeRepo.FindByUserID(ctx, viewer.UserID)
+	switch {
+	case err == nil && store.ID == prod.IDToko:
+		return prod, nil
+	case err != nil && !apperr.IsNotFound(err):
+		return nil, err
+	}
+	return nil, ErrProductNotFound
+}
+
+func (s *productService) findOwned(ctx context.Context, userID, id uint) (*models.Produk, error) {
+	prod, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrProductNotFound, nil)
 	}
-	// 2. Cek kepemilikan
 	store, err := s.storeRepo.FindByUserID(ctx, userID)
-	if err != nil || prod.IDToko != store.ID {
-		return errors.New("unauthorized")
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrStoreNotFound, nil)
 	}
-	// 3. Hapus produk
-	return s.repo.Delete(ctx, id)
+	if prod.IDToko != store.ID {
+		return nil, ErrNotProductOwner
+	}
+	return prod, nil
 }
 
-func (s *productService) UploadImage(ctx context.Context, id uint, file *multipart.FileHeader) (string, error) {
-	// 1. Simpan file di uploads/products
+func (s *productService) UploadImage(ctx context.Context, userID, id uint, file *multipart.FileHeader) (string, error) {
+	// 1. Hanya pemilik toko (atau API key toko tersebut) yang boleh menambah foto
+	if _, err := s.findOwned(ctx, userID, id); err != nil {
+		return "", err
+	}
+
+	// 2. Simpan file di <upload dir>/products
 	filename := fmt.Sprintf("%d_%s", id, filepath.Base(file.Filename))
-	dest := filepath.Join("uploads", "products", filename)
+	dest := filepath.Join(s.uploadDir, "products", filename)
 	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
 		return "", err
 	}
@@ -195,10 +336,10 @@ func (s *productService) UploadImage(ctx context.Context, id uint, file *multipa
 		return "", err
 	}
 
-	// 2. Simpan record foto ke DB
+	// 3. Simpan record foto ke DB
 	photo := &models.FotoProduk{
 		IDProduk:  id,
-		URL:       "/" + filepath.ToSlash(dest),
+		URL:       "/uploads/products/" + filename,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// RoleInfo is a role with the names of its permissions
+type RoleInfo struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name" validate:"omitempty,max=64"` // hanya dipakai saat create
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required,max=64"`
+}
+
+var (
+	ErrRoleNotFound      = apperr.NotFound("role tidak ditemukan")
+	ErrRoleExists        = apperr.Conflict("role dengan nama tersebut sudah ada")
+	ErrInvalidRoleName   = apperr.InvalidField("name", "nama role hanya boleh huruf kecil, angka dan tanda minus (2-64 karakter)")
+	ErrBuiltinRole       = apperr.Forbidden("role bawaan tidak bisa diubah atau dihapus")
+	ErrLastSuperAdmin    = apperr.Conflict("tidak bisa mencabut super-admin terakhir")
+	ErrUnknownPermission = apperr.InvalidField("permissions", "permission tidak dikenal")
+	ErrUserNotFound      = apperr.NotFound("user not found")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,63}$`)
+
+// RoleService manages roles, their permissions and user role assignments
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]RoleInfo, error)
+	ListPermissions(ctx context.Context) ([]PermissionInfo, error)
+	CreateRole(ctx context.Context, req RoleRequest) (*RoleInfo, error)
+	UpdateRole(ctx context.Context, id uint, req RoleRequest) (*RoleInfo, error)
+	DeleteRole(ctx context.Context, id uint) error
+
+	UserRoles(ctx context.Context, userID uint) ([]RoleInfo, error)
+	AssignRole(ctx context.Context, userID uint, req AssignRoleRequest) error
+	// RemoveRole also logs the user out so tokens carrying the role stop working
+	RemoveRole(ctx context.Context, userID uint, roleName string) error
+}
+
+type roleService struct {
+	uow      repository.UnitOfWork
+	roleRepo repository.RoleRepository
+}
+
+func NewRoleService(uow repository.UnitOfWork, roleRepo repository.RoleRepository) RoleService {
+	return &roleService{uow: uow, roleRepo: roleRepo}
+}
+
+func (s *roleService) ListRoles(ctx context.Context) ([]RoleInfo, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toRoleInfos(roles), nil
+}
+
+func (s *roleService) ListPermissions(ctx context.Context) ([]PermissionInfo, error) {
+	perms, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]PermissionInfo, 0, len(perms))
+	for _, p := range perms {
+		list = append(list, PermissionInfo{Name: p.Name, Description: p.Description})
+	}
+	return list, nil
+}
+
+func (s *roleService) CreateRole(ctx context.Context, req RoleRequest) (*RoleInfo, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, ErrInvalidRoleName
+	}
+	var role *models.Role
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		if _, err := repos.Role.FindByName(ctx, req.Name); err == nil {
+			return ErrRoleExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		perms, err := resolvePermissions(ctx, repos.Role, req.Permissions)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		role = &models.Role{
+			Name:        req.Name,
+			Description: req.Description,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Permissions: perms,
+		}
+		return apperr.FromDB(repos.Role.Create(ctx, role), nil, ErrRoleExists)
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := toRoleInfo(role)
+	return &info, nil
+}
+
+// UpdateRole mengganti deskripsi & daftar permission; nama role tetap
+func (s *roleService) UpdateRole(ctx context.Context, id uint, req RoleRequest) (*RoleInfo, error) {
+	var role *models.Role
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		var err error
+		role, err = findRole(ctx, repos.Role, id)
+		if err != nil {
+			return err
+		}
+		if role.Name == models.RoleSuperAdmin {
+			return ErrBuiltinRole
+		}
+		perms, err := resolvePermissions(ctx, repos.Role, req.Permissions)
+		if err != nil {
+			return err
+		}
+		role.Description = req.Description
+		role.UpdatedAt = time.Now()
+		if err := repos.Role.Update(ctx, role); err != nil {
+			return err
+		}
+		if err := repos.Role.ReplacePermissions(ctx, role, perms); err != nil {
+			return err
+		}
+		role.Permissions = perms
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := toRoleInfo(role)
+	return &info, nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, id uint) error {
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		role, err := findRole(ctx, repos.Role, id)
+		if err != nil {
+			return err
+		}
+		if role.Name == models.RoleSuperAdmin || role.Name == models.RoleSupport {
+			return ErrBuiltinRole
+		}
+		return repos.Role.Delete(ctx, id)
+	})
+}
+
+func (s *roleService) UserRoles(ctx context.Context, userID uint) ([]RoleInfo, error) {
+	roles, err := s.roleRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toRoleInfos(roles), nil
+}
+
+func (s *roleService) AssignRole(ctx context.Context, userID uint, req AssignRoleRequest) error {
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		user, err := repos.User.FindByID(ctx, userID)
+		if err != nil {
+			return ErrUserNotFound
+		}
+		role, err := repos.Role.FindByName(ctx, req.Role)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+		if err := repos.Role.Assign(ctx, userID, role.ID, time.Now()); err != nil {
+			return err
+		}
+		if role.Name == models.RoleSuperAdmin {
+			return syncIsAdmin(ctx, repos, user, true)
+		}
+		return nil
+	})
+}
+
+func (s *roleService) RemoveRole(ctx context.Context, userID uint, roleName string) error {
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		user, err := repos.User.FindByID(ctx, userID)
+		if err != nil {
+			return ErrUserNotFound
+		}
+		role, err := repos.Role.FindByName(ctx, roleName)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+		if role.Name == models.RoleSuperAdmin {
+			n, err := repos.Role.CountUsers(ctx, role.ID)
+			if err != nil {
+				return err
+			}
+			if n <= 1 {
+				return ErrLastSuperAdmin
+			}
+			if err := syncIsAdmin(ctx, repos, user, false); err != nil {
+				return err
+			}
+		}
+		if err := repos.Role.Unassign(ctx, userID, role.ID); err != nil {
+			return err
+		}
+		// Access token lama masih membawa role ini, jadi semua sesi dicabut
+		now := time.Now()
+		if err := repos.Session.RevokeByUserID(ctx, userID, now); err != nil {
+			return err
+		}
+		return repos.RefreshToken.RevokeByUserID(ctx, userID, now)
+	})
+}
+
+func findRole(ctx context.Context, repo repository.RoleRepository, id uint) (*models.Role, error) {
+	role, err := repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// resolvePermissions memastikan semua nama permission terdaftar
+func resolvePermissions(ctx context.Context, repo repository.RoleRepository, names []string) ([]*models.Permission, error) {
+	if len(names) == 0 {
+		return []*models.Permission{}, nil
+	}
+	perms, err := repo.FindPermissionsByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		found[p.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, ErrUnknownPermission.WithDetails([]apperr.FieldError{{Field: "permissions", Message: "permission tidak dikenal: " + name}})
+		}
+	}
+	return perms, nil
+}
+
+// syncIsAdmin menjaga kolom lama is_admin tetap sama dengan keanggotaan super-admin
+func syncIsAdmin(ctx context.Context, repos *repository.Repositories, user *models.User, isAdmin bool) error {
+	if user.IsAdmin == isAdmin {
+		return nil
+	}
+	user.IsAdmin = isAdmin
+	user.UpdatedAt = time.Now()
+	return repos.User.Update(ctx, user)
+}
+
+// flattenRoles menghasilkan nama role & gabungan permission (unik, terurut) untuk klaim JWT
+func flattenRoles(roles []*models.Role) (names []string, perms []string) {
+	seen := map[string]bool{}
+	for _, r := range roles {
+		names = append(names, r.Name)
+		for _, p := range r.Permissions {
+			if !seen[p.Name] {
+				seen[p.Name] = true
+				perms = append(perms, p.Name)
+			}
+		}
+	}
+	sort.Strings(perms)
+	return names, perms
+}
+
+func toRoleInfo(r *models.Role) RoleInfo {
+	perms := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		perms = append(perms, p.Name)
+	}
+	sort.Strings(perms)
+	return RoleInfo{ID: r.ID, Name: r.Name, Description: r.Description, Permissions: perms}
+}
+
+func toRoleInfos(roles []*models.Role) []RoleInfo {
+	list := make([]RoleInfo, 0, len(roles))
+	for _, r := range roles {
+		list = append(list, toRoleInfo(r))
+	}
+	return list
+}
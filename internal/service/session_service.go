@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/repository"
+)
+
+// SessionInfo is one active login as shown to its owner
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // sesi yang dipakai request ini
+}
+
+// SessionService manages active logins (sessions) of a user
+type SessionService interface {
+	List(ctx context.Context, userID uint, currentSessionID string) ([]SessionInfo, error)
+	Revoke(ctx context.Context, userID uint, sessionID string) error
+	// RevokeAll logs a user out everywhere (dipakai admin)
+	RevokeAll(ctx context.Context, userID uint) error
+}
+
+var ErrSessionNotFound = apperr.NotFound("session not found")
+
+type sessionService struct {
+	uow         repository.UnitOfWork
+	sessionRepo repository.SessionRepository
+	userRepo    repository.UserRepository
+}
+
+func NewSessionService(
+	uow repository.UnitOfWork,
+	sessionRepo repository.SessionRepository,
+	userRepo repository.UserRepository,
+) SessionService {
+	return &sessionService{
+		uow:         uow,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *sessionService) List(ctx context.Context, userID uint, currentSessionID string) ([]SessionInfo, error) {
+	list, err := s.sessionRepo.ListActiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]SessionInfo, 0, len(list))
+	for _, sess := range list {
+		result = append(result, SessionInfo{
+			ID:         sess.ID,
+			UserAgent:  sess.UserAgent,
+			IPAddress:  sess.IPAddress,
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+			ExpiresAt:  sess.ExpiresAt,
+			Current:    sess.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID uint, sessionID string) error {
+	sess, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return apperr.FromDB(err, ErrSessionNotFound, nil)
+	}
+	if sess.IDUser != userID {
+		return ErrSessionNotFound
+	}
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		now := time.Now()
+		if err := repos.Session.Revoke(ctx, sessionID, now); err != nil {
+			return err
+		}
+		return repos.RefreshToken.RevokeFamily(ctx, sessionID, now)
+	})
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID uint) error {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return apperr.FromDB(err, ErrUserNotFound, nil)
+	}
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		now := time.Now()
+		if err := repos.Session.RevokeByUserID(ctx, userID, now); err != nil {
+			return err
+		}
+		return repos.RefreshToken.RevokeByUserID(ctx, userID, now)
+	})
+}
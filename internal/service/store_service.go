@@ -2,16 +2,16 @@ package service
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"FinalTask/internal/apperr"
 	"FinalTask/internal/models"
 	"FinalTask/internal/repository"
 )
 
 type UpdateStoreRequest struct {
-	NamaToko string `json:"nama_toko"`
-	URLFoto  string `json:"url_foto"`
+	NamaToko string `json:"nama_toko" validate:"required,max=255"`
+	URLFoto  string `json:"url_foto" validate:"max=255"`
 }
 
 type StoreService interface {
@@ -36,7 +36,7 @@ func NewStoreService(repo repository.StoreRepository) StoreService {
 func (s *storeService) GetByUser(ctx context.Context, userID uint) (*models.Toko, error) {
 	store, err := s.repo.FindByUserID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("store not found")
+		return nil, apperr.FromDB(err, ErrStoreNotFound, nil)
 	}
 	return store, nil
 }
@@ -44,7 +44,7 @@ func (s *storeService) GetByUser(ctx context.Context, userID uint) (*models.Toko
 func (s *storeService) Update(ctx context.Context, userID uint, req UpdateStoreRequest) (*models.Toko, error) {
 	store, err := s.repo.FindByUserID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("store not found")
+		return nil, apperr.FromDB(err, ErrStoreNotFound, nil)
 	}
 	store.NamaToko = req.NamaToko
 	store.URLFoto = req.URLFoto
@@ -56,17 +56,13 @@ func (s *storeService) Update(ctx context.Context, userID uint, req UpdateStoreR
 }
 
 func (s *storeService) ListAll(ctx context.Context) ([]*models.Toko, error) {
-	list, err := s.repo.List(ctx)
-	if err != nil {
-		return nil, errors.New("failed to retrieve stores")
-	}
-	return list, nil
+	return s.repo.List(ctx)
 }
 
 func (s *storeService) GetByID(ctx context.Context, id uint) (*models.Toko, error) {
 	store, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return nil, errors.New("store not found")
+		return nil, apperr.FromDB(err, ErrStoreNotFound, nil)
 	}
 	return store, nil
 }
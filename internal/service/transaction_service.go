@@ -2,101 +2,131 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
-	"FinalTask/config"
+	"FinalTask/internal/apperr"
 	"FinalTask/internal/models"
 	"FinalTask/internal/repository"
-
-	"gorm.io/gorm"
 )
 
 type CreateTransactionRequest struct {
-	AlamatPengiriman uint `json:"alamat_pengiriman"`
-	Items            []struct {
-		LogProdukID uint `json:"log_produk_id"`
-		Kuantitas   int  `json:"kuantitas"`
-	} `json:"items"`
-	MethodBayar string `json:"method_bayar"`
+	AlamatPengiriman uint           `json:"alamat_pengiriman" validate:"required"`
+	Items            []CheckoutItem `json:"items" validate:"required,max=100,dive"`
+	MethodBayar      string         `json:"method_bayar" validate:"required,max=255"`
+}
+
+// CheckoutItem is one order line. The server resolves the product's current
+// snapshot itself; log_produk_id is still accepted from older clients but
+// only used to find the product.
+type CheckoutItem struct {
+	IDProduk    uint `json:"id_produk"`
+	LogProdukID uint `json:"log_produk_id"`
+	Kuantitas   int  `json:"kuantitas" validate:"min=1"`
 }
 
 type TransactionService interface {
-	Create(ctx context.Context, userID uint, req CreateTransactionRequest) (*models.Trx, error)
+	// Create returns one order per store in the cart
+	Create(ctx context.Context, userID uint, req CreateTransactionRequest) ([]*models.Trx, error)
 	List(ctx context.Context, userID uint, qs map[string]string) ([]*models.Trx, error)
 	GetByID(ctx context.Context, userID, id uint) (*models.Trx, error)
+
+	// Store API key: pesanan yang masuk ke toko, bukan pembelian pemiliknya
+	ListByStore(ctx context.Context, storeID uint, qs map[string]string) ([]*models.Trx, error)
+	GetByStore(ctx context.Context, storeID, id uint) (*models.Trx, error)
+	// Penjual login dengan JWT: sama seperti di atas untuk toko milik userID
+	ListForSeller(ctx context.Context, userID uint, qs map[string]string) ([]*models.Trx, error)
+	GetForSeller(ctx context.Context, userID, id uint) (*models.Trx, error)
+
+	// Staff (order:read): lihat transaksi semua user
+	ListAll(ctx context.Context, qs map[string]string) ([]*models.Trx, error)
+	GetAnyByID(ctx context.Context, id uint) (*models.Trx, error)
+
+	// UpdateStatus moves an order through the status state machine
+	UpdateStatus(ctx context.Context, actor OrderActor, id uint, req UpdateOrderStatusRequest) (*models.Trx, error)
+	// Cancel (pembeli) & Reject (penjual) sebelum pengiriman; stok dikembalikan
+	Cancel(ctx context.Context, actor OrderActor, id uint, req CancelOrderRequest) (*models.Trx, error)
+	Reject(ctx context.Context, actor OrderActor, id uint, req CancelOrderRequest) (*models.Trx, error)
 }
 
+var (
+	// Transaksi milik user lain juga dilaporkan "not found"
+	ErrTransactionNotFound = apperr.NotFound("transaction not found")
+	ErrUnknownAddress      = apperr.InvalidField("alamat_pengiriman", "alamat pengiriman tidak ditemukan")
+	ErrItemsUnavailable    = apperr.Validation("beberapa item tidak bisa dibeli")
+	// details menyebut item mana yang stoknya kurang
+	ErrOutOfStock = apperr.Conflict("stok tidak mencukupi")
+)
+
 type transactionService struct {
-	trxRepo     repository.TransactionRepository
-	productRepo repository.ProductRepository
+	uow       repository.UnitOfWork
+	trxRepo   repository.TransactionRepository
+	userRepo  repository.UserRepository
+	storeRepo repository.StoreRepository
 }
 
 func NewTransactionService(
+	uow repository.UnitOfWork,
 	trxRepo repository.TransactionRepository,
-	productRepo repository.ProductRepository,
+	userRepo repository.UserRepository,
+	storeRepo repository.StoreRepository,
 ) TransactionService {
 	return &transactionService{
-		trxRepo:     trxRepo,
-		productRepo: productRepo,
+		uow:       uow,
+		trxRepo:   trxRepo,
+		userRepo:  userRepo,
+		storeRepo: storeRepo,
 	}
 }
 
-func (s *transactionService) Create(ctx context.Context, userID uint, req CreateTransactionRequest) (*models.Trx, error) {
-	// tempID akan kita gunakan untuk reload
-	var tempID uint
+// Create checks out the cart. Status pesanan dijalankan oleh penjualnya, jadi
+// keranjang berisi produk dari beberapa toko dipecah menjadi satu transaksi
+// per toko (urut sesuai item pertama tiap toko), semuanya dalam satu tx.
+func (s *transactionService) Create(ctx context.Context, userID uint, req CreateTransactionRequest) ([]*models.Trx, error) {
+	// Checkout hanya untuk akun yang email & nomor teleponnya terverifikasi
+	if err := ensureVerified(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	// ID transaksi yang dibuat, untuk reload setelah commit
+	var ids []uint
 
-	err := config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		invoiceCode := fmt.Sprintf("INV-%d-%d", time.Now().UnixNano(), userID)
-		now := time.Now()
-		trx := &models.Trx{
-			IDUser:           userID,
-			AlamatPengiriman: req.AlamatPengiriman,
-			MethodBayar:      req.MethodBayar,
-			KodeInvoice:      invoiceCode,
-			CreatedAt:        now,
-			UpdatedAt:        now,
-		}
-		if err := tx.Create(trx).Error; err != nil {
+	// Semua query checkout memakai repository yang terikat ke tx yang sama,
+	// jadi kegagalan di item mana pun ikut membatalkan pengurangan stok
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		// Alamat harus milik pembeli sendiri
+		addr, err := repos.Address.FindByID(ctx, req.AlamatPengiriman)
+		if err != nil {
+			return apperr.FromDB(err, ErrUnknownAddress, nil)
+		}
+		if addr.IDUser != userID {
+			return ErrUnknownAddress
+		}
+
+		lines, err := resolveItems(ctx, repos, req.Items)
+		if err != nil {
 			return err
 		}
-		tempID = trx.ID
+		groups := groupByStore(lines)
 
-		total := 0
-		for _, item := range req.Items {
-			logEntry, err := s.productRepo.FindLogByID(ctx, item.LogProdukID)
-			if err != nil {
-				return err
+		invoiceCode := fmt.Sprintf("INV-%d-%d", time.Now().UnixNano(), userID)
+		// Stok dikurangi di tx yang sama dengan pengurangan bersyarat; bila ada
+		// item yang stoknya kurang, seluruh checkout dibatalkan (rollback)
+		var outOfStock []apperr.FieldError
+		for n, group := range groups {
+			code := invoiceCode
+			if len(groups) > 1 {
+				code = fmt.Sprintf("%s-%d", invoiceCode, n+1)
 			}
-			price, err := strconv.Atoi(logEntry.HargaKonsumen)
+			trx, short, err := createStoreOrder(ctx, repos, userID, req, code, group)
 			if err != nil {
-				return fmt.Errorf("invalid harga konsumen: %v", err)
-			}
-			detail := &models.DetailTrx{
-				IDTrx:       trx.ID,
-				IDLogProduk: logEntry.ID,
-				IDToko:      logEntry.IDToko,
-				Kuantitas:   item.Kuantitas,
-				HargaTotal:  item.Kuantitas * price,
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
-			}
-			if err := tx.Create(detail).Error; err != nil {
-				return err
-			}
-			total += detail.HargaTotal
-
-			if err := s.productRepo.UpdateStock(ctx, logEntry.IDProduk, item.Kuantitas); err != nil {
 				return err
 			}
+			outOfStock = append(outOfStock, short...)
+			ids = append(ids, trx.ID)
 		}
-
-		trx.HargaTotal = total
-		trx.UpdatedAt = time.Now()
-		if err := tx.Save(trx).Error; err != nil {
-			return err
+		if len(outOfStock) > 0 {
+			return ErrOutOfStock.WithDetails(outOfStock)
 		}
 		return nil
 	})
@@ -105,29 +135,232 @@ func (s *transactionService) Create(ctx context.Context, userID uint, req Create
 	}
 
 	// Setelah transaction commit, reload lengkap dengan DetailTrx
-	full, err := s.trxRepo.FindByID(ctx, userID, tempID)
+	orders := make([]*models.Trx, 0, len(ids))
+	for _, id := range ids {
+		full, err := s.trxRepo.FindByID(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, full)
+	}
+	return orders, nil
+}
+
+// createStoreOrder membuat satu transaksi untuk baris-baris dari satu toko.
+// Baris yang stoknya kurang dikembalikan sebagai short, pemanggil yang
+// membatalkan seluruh checkout.
+func createStoreOrder(ctx context.Context, repos *repository.Repositories, userID uint, req CreateTransactionRequest, code string, lines []checkoutLine) (*models.Trx, []apperr.FieldError, error) {
+	now := time.Now()
+	trx := &models.Trx{
+		IDUser:           userID,
+		AlamatPengiriman: req.AlamatPengiriman,
+		MethodBayar:      req.MethodBayar,
+		Status:           models.OrderPendingPayment,
+		KodeInvoice:      code,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := repos.Transaction.Create(ctx, trx); err != nil {
+		return nil, nil, err
+	}
+	if err := repos.Transaction.CreateEvent(ctx, &models.OrderEvent{
+		IDTrx:     trx.ID,
+		ToStatus:  models.OrderPendingPayment,
+		IDActor:   &userID,
+		ActorRole: models.ActorBuyer,
+		CreatedAt: now,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	var total models.Money
+	var short []apperr.FieldError
+	for _, line := range lines {
+		ok, err := repos.Product.DecrementStock(ctx, line.log.IDProduk, line.kuantitas)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			short = append(short, apperr.FieldError{
+				Field:   fmt.Sprintf("items[%d].kuantitas", line.index),
+				Message: fmt.Sprintf("%s: stok tidak mencukupi", line.log.NamaProduk),
+			})
+			continue
+		}
+
+		detail := &models.DetailTrx{
+			IDTrx:       trx.ID,
+			IDLogProduk: line.log.ID,
+			IDToko:      line.log.IDToko,
+			Kuantitas:   line.kuantitas,
+			HargaTotal:  line.log.HargaKonsumen.Mul(line.kuantitas),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if err := repos.Transaction.CreateDetail(ctx, detail); err != nil {
+			return nil, nil, err
+		}
+		total += detail.HargaTotal
+	}
+
+	trx.HargaTotal = total
+	trx.UpdatedAt = time.Now()
+	if err := repos.Transaction.Update(ctx, trx); err != nil {
+		return nil, nil, err
+	}
+	return trx, short, nil
+}
+
+// groupByStore memecah baris checkout per toko, urut sesuai kemunculan
+// pertama tiap toko di keranjang
+func groupByStore(lines []checkoutLine) [][]checkoutLine {
+	var groups [][]checkoutLine
+	pos := map[uint]int{}
+	for _, line := range lines {
+		i, ok := pos[line.log.IDToko]
+		if !ok {
+			i = len(groups)
+			pos[line.log.IDToko] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], line)
+	}
+	return groups
+}
+
+// checkoutLine is an order line resolved to the snapshot it is priced from
+type checkoutLine struct {
+	index     int // posisi di req.Items, untuk pesan error
+	log       *models.LogProduk
+	kuantitas int
+}
+
+// resolveItems memetakan setiap item ke snapshot terkini produknya. Semua
+// item diperiksa dulu sehingga klien menerima seluruh baris yang tidak bisa
+// dibeli dalam satu respons 422.
+func resolveItems(ctx context.Context, repos *repository.Repositories, items []CheckoutItem) ([]checkoutLine, error) {
+	lines := make([]checkoutLine, 0, len(items))
+	var problems []apperr.FieldError
+	for i, item := range items {
+		logEntry, problem, err := resolveItem(ctx, repos, item)
+		if err != nil {
+			return nil, err
+		}
+		if problem != "" {
+			field := fmt.Sprintf("items[%d].id_produk", i)
+			if item.IDProduk == 0 && item.LogProdukID != 0 {
+				field = fmt.Sprintf("items[%d].log_produk_id", i)
+			}
+			problems = append(problems, apperr.FieldError{Field: field, Message: problem})
+			continue
+		}
+		lines = append(lines, checkoutLine{index: i, log: logEntry, kuantitas: item.Kuantitas})
+	}
+	if len(problems) > 0 {
+		return nil, ErrItemsUnavailable.WithDetails(problems)
+	}
+	return lines, nil
+}
+
+// resolveItem returns the current snapshot of the item's product, or a
+// message explaining why the line cannot be bought
+func resolveItem(ctx context.Context, repos *repository.Repositories, item CheckoutItem) (*models.LogProduk, string, error) {
+	produkID := item.IDProduk
+	if produkID == 0 {
+		if item.LogProdukID == 0 {
+			return nil, "wajib diisi", nil
+		}
+		requested, err := repos.Product.FindLogByID(ctx, item.LogProdukID)
+		if apperr.IsNotFound(err) {
+			return nil, "produk tidak ditemukan", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		produkID = requested.IDProduk
+	}
+
+	prod, err := repos.Product.FindForCheckout(ctx, produkID)
+	switch {
+	case apperr.IsNotFound(err):
+		return nil, "produk tidak ditemukan", nil
+	case err != nil:
+		return nil, "", err
+	case prod.DeletedAt.Valid:
+		return nil, "produk sudah dihapus penjual", nil
+	case prod.Status != models.ProdukPublished:
+		return nil, "produk sedang tidak dijual", nil
+	case prod.IDLogProduk == nil:
+		return nil, "", fmt.Errorf("produk %d belum punya snapshot", prod.ID)
+	}
+	logEntry, err := repos.Product.FindLogByID(ctx, *prod.IDLogProduk)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return full, nil
+	return logEntry, "", nil
 }
 
 func (s *transactionService) List(ctx context.Context, userID uint, qs map[string]string) ([]*models.Trx, error) {
-	page, limit := 1, 10
-	if p, ok := qs["page"]; ok {
-		fmt.Sscanf(p, "%d", &page)
-	}
-	if l, ok := qs["limit"]; ok {
-		fmt.Sscanf(l, "%d", &limit)
+	offset, limit, err := parsePage(qs)
+	if err != nil {
+		return nil, err
 	}
 	// ListByUserID should preload DetailTrx and related
-	return s.trxRepo.ListByUserID(ctx, userID, (page-1)*limit, limit)
+	return s.trxRepo.ListByUserID(ctx, userID, offset, limit)
 }
 
 func (s *transactionService) GetByID(ctx context.Context, userID, id uint) (*models.Trx, error) {
 	trx, err := s.trxRepo.FindByID(ctx, userID, id)
 	if err != nil {
-		return nil, errors.New("transaction not found or unauthorized")
+		return nil, apperr.FromDB(err, ErrTransactionNotFound, nil)
+	}
+	return trx, nil
+}
+
+func (s *transactionService) ListByStore(ctx context.Context, storeID uint, qs map[string]string) ([]*models.Trx, error) {
+	offset, limit, err := parsePage(qs)
+	if err != nil {
+		return nil, err
+	}
+	return s.trxRepo.ListByStoreID(ctx, storeID, offset, limit)
+}
+
+func (s *transactionService) GetByStore(ctx context.Context, storeID, id uint) (*models.Trx, error) {
+	trx, err := s.trxRepo.FindByStoreID(ctx, storeID, id)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrTransactionNotFound, nil)
+	}
+	return trx, nil
+}
+
+func (s *transactionService) ListForSeller(ctx context.Context, userID uint, qs map[string]string) ([]*models.Trx, error) {
+	store, err := s.storeRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrStoreNotFound, nil)
+	}
+	return s.ListByStore(ctx, store.ID, qs)
+}
+
+func (s *transactionService) GetForSeller(ctx context.Context, userID, id uint) (*models.Trx, error) {
+	store, err := s.storeRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrStoreNotFound, nil)
+	}
+	return s.GetByStore(ctx, store.ID, id)
+}
+
+func (s *transactionService) ListAll(ctx context.Context, qs map[string]string) ([]*models.Trx, error) {
+	offset, limit, err := parsePage(qs)
+	if err != nil {
+		return nil, err
+	}
+	return s.trxRepo.ListAll(ctx, offset, limit)
+}
+
+func (s *transactionService) GetAnyByID(ctx context.Context, id uint) (*models.Trx, error) {
+	trx, err := s.trxRepo.FindAnyByID(ctx, id)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrTransactionNotFound, nil)
 	}
 	return trx, nil
 }
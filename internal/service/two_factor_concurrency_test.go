@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"FinalTask/config"
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+	"FinalTask/internal/service"
+	"FinalTask/utils"
+)
+
+const (
+	twoFactorGuesses     = 30 // tebakan paralel per challenge
+	twoFactorRounds      = 10 // race tidak selalu muncul, jadi diulang
+	twoFactorMaxAttempts = 5
+)
+
+// TestVerifyTwoFactorAttemptLimit fires twoFactorGuesses wrong 6-digit codes
+// at one login challenge at once, for twoFactorRounds fresh challenges. At
+// most twoFactorMaxAttempts of them may be checked against the TOTP secret;
+// the rest must be refused (invalid challenge or account backoff), and the
+// stored attempt count must never pass the limit.
+func TestVerifyTwoFactorAttemptLimit(t *testing.T) {
+	ctx := context.Background()
+	parallel(t)
+	db := openTestDB(t, twoFactorGuesses)
+	repos := repository.NewRepositories(db)
+
+	now := time.Now()
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := now.UnixNano()
+	user := &models.User{
+		Nama:            "pengguna 2fa",
+		Email:           fmt.Sprintf("2fa-%d@example.com", run),
+		NoTelp:          fmt.Sprintf("09%d", run%1e10),
+		Password:        "-",
+		TOTPSecret:      secret,
+		TOTPEnabledAt:   &now,
+		EmailVerifiedAt: &now,
+		PhoneVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := repos.User.Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	// Kode 6 digit yang pasti salah untuk periode sekarang ±1
+	wrong := ""
+	for n := 0; n < 1000000 && wrong == ""; n++ {
+		code := fmt.Sprintf("%06d", n)
+		if _, ok := utils.ValidateTOTP(secret, code, now, 0); !ok {
+			wrong = code
+		}
+	}
+
+	// Batas akun & IP dibuat longgar agar yang diuji hanya batas per challenge
+	authCfg := config.AuthConfig{
+		LoginMaxAttempts:     1000,
+		LoginLockout:         time.Minute,
+		LoginBackoffBase:     time.Nanosecond,
+		LoginBackoffMax:      time.Nanosecond,
+		LoginIPMaxFailures:   1000,
+		LoginIPWindow:        time.Minute,
+		TwoFactorMaxAttempts: twoFactorMaxAttempts,
+	}
+	auth := service.NewAuthService(repository.NewUnitOfWork(db), repos.User, repos.RefreshToken, repos.LoginAttempt,
+		repos.Challenge, repos.Setting, repos.OIDCState, nil, nil, nil, nil, config.JWTConfig{}, authCfg, config.OIDCConfig{})
+
+	for round := 0; round < twoFactorRounds; round++ {
+		token := fmt.Sprintf("challenge-%d-%d", run, round)
+		challenge := &models.LoginChallenge{
+			IDUser:    user.ID,
+			TokenHash: utils.HashToken(token),
+			Purpose:   models.ChallengeTOTP,
+			ExpiresAt: now.Add(5 * time.Minute),
+			CreatedAt: now,
+		}
+		if err := repos.Challenge.Create(ctx, challenge); err != nil {
+			t.Fatalf("create challenge: %v", err)
+		}
+
+		start := make(chan struct{})
+		errs := make([]error, twoFactorGuesses)
+		var wg sync.WaitGroup
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				_, errs[i] = auth.VerifyTwoFactor(ctx, service.TwoFactorChallengeRequest{ChallengeToken: token, Code: wrong},
+					service.ClientInfo{IPAddress: "203.0.113.7"})
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+
+		// Ditolak sebelum kode diperiksa: challenge habis atau jeda backoff akun
+		checked := 0
+		for i, err := range errs {
+			var blocked *service.LoginBlockedError
+			switch {
+			case errors.Is(err, service.ErrInvalidTwoFactorCode):
+				checked++
+			case errors.Is(err, service.ErrInvalidChallenge), errors.As(err, &blocked):
+			default:
+				t.Errorf("round %d guess %d: unexpected result: %v", round, i, err)
+			}
+		}
+		if checked > twoFactorMaxAttempts {
+			t.Errorf("round %d: codes checked = %d, want at most %d", round, checked, twoFactorMaxAttempts)
+		}
+
+		stored, err := repos.Challenge.FindByHash(ctx, utils.HashToken(token))
+		if err != nil {
+			t.Fatalf("reload challenge: %v", err)
+		}
+		if stored.Attempts > twoFactorMaxAttempts {
+			t.Errorf("round %d: stored attempts = %d, want at most %d", round, stored.Attempts, twoFactorMaxAttempts)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"FinalTask/config"
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/repository"
+	"FinalTask/utils"
+)
+
+// Jumlah kode pemulihan yang dibuat setiap kali 2FA diaktifkan/diperbarui
+const recoveryCodeCount = 10
+
+// TwoFactorStatus is shown on GET /me/2fa
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at"`
+	Required          bool       `json:"required"` // kebijakan mewajibkan 2FA untuk akun ini
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+// TOTPSetup carries the secret to enter into an authenticator app. The
+// frontend renders ProvisioningURI as a QR code.
+type TOTPSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodes are shown once; only their hashes are stored
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code (or, where allowed, a recovery code)
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=64"`
+}
+
+// DisableTwoFactorRequest needs both the password and a current code
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=64"`
+}
+
+// SecurityPolicy is the admin-controlled security switchboard
+type SecurityPolicy struct {
+	Require2FAForAdmins bool `json:"require_2fa_for_admins"`
+}
+
+var (
+	ErrTwoFactorEnabled     = apperr.Conflict("2FA sudah aktif")
+	ErrTwoFactorNotEnabled  = apperr.Conflict("2FA belum aktif")
+	ErrTwoFactorSetupNeeded = apperr.Conflict("jalankan setup 2FA terlebih dahulu")
+	ErrInvalidTwoFactorCode = apperr.Unauthorized("kode 2FA salah atau sudah dipakai")
+	ErrTwoFactorRequired    = apperr.Forbidden("2FA wajib untuk akun admin dan tidak bisa dinonaktifkan")
+)
+
+// TwoFactorService manages TOTP enrolment of the current user and the
+// admin policy that makes 2FA mandatory for IsAdmin users
+type TwoFactorService interface {
+	Status(ctx context.Context, userID uint) (*TwoFactorStatus, error)
+	Setup(ctx context.Context, userID uint) (*TOTPSetup, error)
+	Enable(ctx context.Context, userID uint, req TwoFactorCodeRequest) (*RecoveryCodes, error)
+	Disable(ctx context.Context, userID uint, req DisableTwoFactorRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, req TwoFactorCodeRequest) (*RecoveryCodes, error)
+
+	// Admin
+	Policy(ctx context.Context) (*SecurityPolicy, error)
+	SetPolicy(ctx context.Context, policy SecurityPolicy) (*SecurityPolicy, error)
+	// Reset turns 2FA off for a user who lost their device and logs them out
+	Reset(ctx context.Context, userID uint) error
+}
+
+type twoFactorService struct {
+	uow          repository.UnitOfWork
+	userRepo     repository.UserRepository
+	recoveryRepo repository.RecoveryCodeRepository
+	settingRepo  repository.SettingRepository
+	authCfg      config.AuthConfig
+}
+
+func NewTwoFactorService(
+	uow repository.UnitOfWork,
+	userRepo repository.UserRepository,
+	recoveryRepo repository.RecoveryCodeRepository,
+	settingRepo repository.SettingRepository,
+	authCfg config.AuthConfig,
+) TwoFactorService {
+	return &twoFactorService{
+		uow:          uow,
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		settingRepo:  settingRepo,
+		authCfg:      authCfg,
+	}
+}
+
+func (s *twoFactorService) Status(ctx context.Context, userID uint) (*TwoFactorStatus, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	required, err := twoFactorRequired(ctx, s.settingRepo, user)
+	if err != nil {
+		return nil, err
+	}
+	status := &TwoFactorStatus{
+		Enabled:   user.HasTwoFactor(),
+		EnabledAt: user.TOTPEnabledAt,
+		Required:  required,
+	}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.recoveryRepo.CountUnused(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func (s *twoFactorService) Setup(ctx context.Context, userID uint) (*TOTPSetup, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return setupTOTP(ctx, s.userRepo, user, s.authCfg.TOTPIssuer)
+}
+
+func (s *twoFactorService) Enable(ctx context.Context, userID uint, req TwoFactorCodeRequest) (*RecoveryCodes, error) {
+	var codes []string
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		user, err := repos.User.FindByID(ctx, userID)
+		if err != nil {
+			return ErrUserNotFound
+		}
+		codes, err = enableTOTP(ctx, repos, user, req.Code, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID uint, req DisableTwoFactorRequest) error {
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		user, err := repos.User.FindByID(ctx, userID)
+		if err != nil {
+			return ErrUserNotFound
+		}
+		if !user.HasTwoFactor() {
+			return ErrTwoFactorNotEnabled
+		}
+		required, err := twoFactorRequired(ctx, repos.Setting, user)
+		if err != nil {
+			return err
+		}
+		if required {
+			return ErrTwoFactorRequired
+		}
+		if !utils.CheckPasswordHash(req.Password, user.Password) {
+			return ErrWrongPassword
+		}
+		if err := checkSecondFactor(ctx, repos, user, req.Code, time.Now()); err != nil {
+			return err
+		}
+		if err := repos.User.SetTOTP(ctx, userID, "", nil); err != nil {
+			return err
+		}
+		return repos.RecoveryCode.DeleteByUserID(ctx, userID)
+	})
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint, req TwoFactorCodeRequest) (*RecoveryCodes, error) {
+	var codes []string
+	err := s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		user, err := repos.User.FindByID(ctx, userID)
+		if err != nil {
+			return ErrUserNotFound
+		}
+		if !user.HasTwoFactor() {
+			return ErrTwoFactorNotEnabled
+		}
+		// Hanya kode TOTP, kode pemulihan tidak boleh dipakai membuat kode baru
+		if !isTOTPCode(req.Code) {
+			return ErrInvalidTwoFactorCode
+		}
+		if err := checkSecondFactor(ctx, repos, user, req.Code, time.Now()); err != nil {
+			return err
+		}
+		codes, err = newRecoveryCodes(ctx, repos, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+func (s *twoFactorService) Policy(ctx context.Context) (*SecurityPolicy, error) {
+	value, err := s.settingRepo.Get(ctx, models.SettingRequire2FAForAdmins, "false")
+	if err != nil {
+		return nil, err
+	}
+	required, _ := strconv.ParseBool(value)
+	return &SecurityPolicy{Require2FAForAdmins: required}, nil
+}
+
+func (s *twoFactorService) SetPolicy(ctx context.Context, policy SecurityPolicy) (*SecurityPolicy, error) {
+	value := strconv.FormatBool(policy.Require2FAForAdmins)
+	if err := s.settingRepo.Set(ctx, models.SettingRequire2FAForAdmins, value, time.Now()); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (s *twoFactorService) Reset(ctx context.Context, userID uint) error {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return ErrUserNotFound
+	}
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.User.SetTOTP(ctx, userID, "", nil); err != nil {
+			return err
+		}
+		if err := repos.RecoveryCode.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := repos.Session.RevokeByUserID(ctx, userID, now); err != nil {
+			return err
+		}
+		return repos.RefreshToken.RevokeByUserID(ctx, userID, now)
+	})
+}
+
+// twoFactorRequired: kebijakan admin mewajibkan 2FA untuk user IsAdmin
+func twoFactorRequired(ctx context.Context, settings repository.SettingRepository, user *models.User) (bool, error) {
+	if !user.IsAdmin {
+		return false, nil
+	}
+	value, err := settings.Get(ctx, models.SettingRequire2FAForAdmins, "false")
+	if err != nil {
+		return false, err
+	}
+	required, _ := strconv.ParseBool(value)
+	return required, nil
+}
+
+// setupTOTP membuat secret baru yang belum aktif sampai dikonfirmasi dengan
+// satu kode (enableTOTP). Memanggil ulang mengganti secret sebelumnya.
+func setupTOTP(ctx context.Context, users repository.UserRepository, user *models.User, issuer string) (*TOTPSetup, error) {
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorEnabled
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := users.SetTOTP(ctx, user.ID, secret, nil); err != nil {
+		return nil, err
+	}
+	return &TOTPSetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(issuer, user.Email, secret),
+	}, nil
+}
+
+// enableTOTP mengaktifkan 2FA setelah kode pertama dari authenticator cocok
+// dan mengembalikan kode pemulihan baru
+func enableTOTP(ctx context.Context, repos *repository.Repositories, user *models.User, code string, now time.Time) ([]string, error) {
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorSetupNeeded
+	}
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(code), now, 0)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	if err := repos.User.SetTOTP(ctx, user.ID, user.TOTPSecret, &now); err != nil {
+		return nil, err
+	}
+	// Kode yang baru dipakai untuk aktivasi tidak boleh dipakai login lagi
+	if _, err := repos.User.UseTOTPStep(ctx, user.ID, step); err != nil {
+		return nil, err
+	}
+	user.TOTPEnabledAt = &now
+	return newRecoveryCodes(ctx, repos, user.ID)
+}
+
+// checkSecondFactor menerima kode TOTP 6 digit atau kode pemulihan.
+// Keduanya sekali pakai.
+func checkSecondFactor(ctx context.Context, repos *repository.Repositories, user *models.User, code string, now time.Time) error {
+	code = strings.TrimSpace(code)
+	if code == "" || !user.HasTwoFactor() {
+		return ErrInvalidTwoFactorCode
+	}
+	if isTOTPCode(code) {
+		step, ok := utils.ValidateTOTP(user.TOTPSecret, code, now, user.TOTPLastStep)
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		// Atomik: dua request paralel dengan kode yang sama, hanya satu lolos
+		used, err := repos.User.UseTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+	used, err := repos.RecoveryCode.Use(ctx, user.ID, utils.HashToken(utils.NormalizeRecoveryCode(code)), now)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func newRecoveryCodes(ctx context.Context, repos *repository.Repositories, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	}
+	if err := repos.RecoveryCode.ReplaceForUser(ctx, userID, hashes, time.Now()); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -3,25 +3,25 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"time"
 
+	"FinalTask/internal/apperr"
 	"FinalTask/internal/models"
 	"FinalTask/internal/repository"
 )
 
 // UpdateUserRequest represents payload for updating user profile
 type UpdateUserRequest struct {
-	Nama         string `json:"nama"`
-	TanggalLahir string `json:"tanggal_lahir"` // expects "YYYY-MM-DD"
-	JenisKelamin string `json:"jenis_kelamin"`
+	Nama         string `json:"nama" validate:"required,max=255"`
+	TanggalLahir string `json:"tanggal_lahir" validate:"omitempty,date"` // expects "YYYY-MM-DD"
+	JenisKelamin string `json:"jenis_kelamin" validate:"max=50"`
 	Tentang      string `json:"tentang"`
-	Pekerjaan    string `json:"pekerjaan"`
-	IDProvinsi   string `json:"id_provinsi"`
-	IDKota       string `json:"id_kota"`
+	Pekerjaan    string `json:"pekerjaan" validate:"max=255"`
+	IDProvinsi   string `json:"id_provinsi" validate:"required,numeric"`
+	IDKota       string `json:"id_kota" validate:"required,numeric"`
 }
 
 // UpdateProfileResult wraps the updated user plus region names
@@ -31,6 +31,14 @@ type UpdateProfileResult struct {
 	CityName     string       `json:"city_name"`
 }
 
+var (
+	ErrProvinceNotFound = apperr.InvalidField("id_provinsi", "province not found")
+	ErrCityNotFound     = apperr.InvalidField("id_kota", "city not found in selected province")
+	ErrInvalidBirthDate = apperr.InvalidField("tanggal_lahir", "invalid tanggal_lahir format, expected YYYY-MM-DD")
+	ErrRegionNotFound   = apperr.NotFound("wilayah tidak ditemukan")
+	ErrRegionAPI        = apperr.Upstream("layanan data wilayah tidak tersedia, coba lagi nanti")
+)
+
 // UserService defines methods for user profile management
 type UserService interface {
 	GetByID(ctx context.Context, id uint) (*models.User, error)
@@ -38,18 +46,20 @@ type UserService interface {
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo          repository.UserRepository
+	regionBaseURL string
 }
 
 // NewUserService constructs a UserService with injected UserRepository
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+// and the base URL of the region API used to validate province/city IDs
+func NewUserService(repo repository.UserRepository, regionBaseURL string) UserService {
+	return &userService{repo: repo, regionBaseURL: regionBaseURL}
 }
 
 func (s *userService) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, apperr.FromDB(err, ErrUserNotFound, nil)
 	}
 	return user, nil
 }
@@ -58,13 +68,13 @@ func (s *userService) Update(ctx context.Context, id uint, req UpdateUserRequest
 	// 1) Load existing user
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, apperr.FromDB(err, ErrUserNotFound, nil)
 	}
 
 	// 2) Fetch and validate provinces
-	provinces, err := fetchRegions("https://www.emsifa.com/api-wilayah-indonesia/api/provinces.json")
+	provinces, err := fetchRegions(s.regionBaseURL + "/provinces.json")
 	if err != nil {
-		return nil, fmt.Errorf("cannot load provinces: %v", err)
+		return nil, err
 	}
 	var provinceName string
 	for _, p := range provinces {
@@ -74,14 +84,14 @@ func (s *userService) Update(ctx context.Context, id uint, req UpdateUserRequest
 		}
 	}
 	if provinceName == "" {
-		return nil, errors.New("province not found")
+		return nil, ErrProvinceNotFound
 	}
 
 	// 3) Fetch and validate regencies for that province
-	regURL := fmt.Sprintf("https://www.emsifa.com/api-wilayah-indonesia/api/regencies/%s.json", req.IDProvinsi)
+	regURL := fmt.Sprintf("%s/regencies/%s.json", s.regionBaseURL, req.IDProvinsi)
 	regencies, err := fetchRegions(regURL)
 	if err != nil {
-		return nil, fmt.Errorf("cannot load regencies: %v", err)
+		return nil, err
 	}
 	var cityName string
 	for _, r := range regencies {
@@ -91,7 +101,7 @@ func (s *userService) Update(ctx context.Context, id uint, req UpdateUserRequest
 		}
 	}
 	if cityName == "" {
-		return nil, errors.New("city not found in selected province")
+		return nil, ErrCityNotFound
 	}
 
 	// 4) Apply updates to user struct
@@ -105,7 +115,7 @@ func (s *userService) Update(ctx context.Context, id uint, req UpdateUserRequest
 		if dt, perr := time.Parse("2006-01-02", req.TanggalLahir); perr == nil {
 			user.TanggalLahir = &dt
 		} else {
-			return nil, errors.New("invalid tanggal_lahir format, expected YYYY-MM-DD")
+			return nil, ErrInvalidBirthDate
 		}
 	}
 	user.UpdatedAt = time.Now()
@@ -143,19 +153,30 @@ var httpClient = &http.Client{
 
 // fetchRegions fetches and decodes a list of regions from the given URL
 func fetchRegions(url string) ([]regionEntry, error) {
+	var list []regionEntry
+	if err := getRegionJSON(url, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// getRegionJSON decodes a region API response into out. A 404 means the
+// requested ID does not exist; any other failure is an upstream error.
+func getRegionJSON(url string, out interface{}) error {
 	resp, err := httpClient.Get(url)
 	if err != nil {
-		return nil, err
+		return ErrRegionAPI.Wrap(err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrRegionNotFound
+	case resp.StatusCode != http.StatusOK:
+		return ErrRegionAPI.Wrap(fmt.Errorf("HTTP %d dari %s", resp.StatusCode, url))
 	}
-
-	var list []regionEntry
-	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
-		return nil, err
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return ErrRegionAPI.Wrap(err)
 	}
-	return list, nil
+	return nil
 }
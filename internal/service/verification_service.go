@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"FinalTask/config"
+	"FinalTask/internal/apperr"
+	"FinalTask/internal/models"
+	"FinalTask/internal/notify"
+	"FinalTask/internal/repository"
+	"FinalTask/utils"
+
+	"gorm.io/gorm"
+)
+
+const otpDigits = 6
+
+// VerificationStatus shows which contact fields of the user are verified
+type VerificationStatus struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	NoTelp        string `json:"no_telp"`
+	PhoneVerified bool   `json:"phone_verified"`
+}
+
+// VerificationSent describes a freshly sent code (the code itself is never returned)
+type VerificationSent struct {
+	Channel     string `json:"channel"`
+	Target      string `json:"target"` // alamat tujuan yang disamarkan
+	ExpiresIn   int64  `json:"expires_in"`
+	ResendAfter int64  `json:"resend_after"`
+}
+
+type VerifyRequest struct {
+	Code string `json:"code" validate:"required,max=64"`
+}
+
+// SetPhoneRequest is the body of PUT /auth/verification/phone
+type SetPhoneRequest struct {
+	NoTelp string `json:"no_telp" validate:"required,phone"`
+}
+
+// ThrottleError is returned when a code is requested too often
+type ThrottleError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return fmt.Sprintf("terlalu sering meminta kode, coba lagi dalam %d detik", int(e.RetryAfter.Seconds())+1)
+}
+
+func (e *ThrottleError) Unwrap() error {
+	return apperr.Throttled(e.Error(), e.RetryAfter)
+}
+
+var (
+	ErrAccountNotVerified = apperr.Forbidden("akun belum terverifikasi, verifikasi email dan nomor telepon terlebih dahulu")
+	ErrUnknownChannel     = apperr.BadRequest("channel verifikasi harus email atau phone")
+	ErrAlreadyVerified    = apperr.Conflict("sudah terverifikasi")
+	ErrInvalidOTP         = apperr.BadRequest("kode verifikasi salah atau kedaluwarsa")
+	ErrOTPTooManyAttempts = apperr.TooManyRequests("terlalu banyak percobaan, minta kode baru")
+	ErrPhoneMissing       = apperr.BadRequest("nomor telepon belum diisi")
+	ErrInvalidPhone       = apperr.InvalidField("no_telp", "nomor telepon tidak valid")
+	ErrPhoneTaken         = apperr.Conflict("nomor telepon sudah terdaftar")
+)
+
+// VerificationService sends and checks OTPs for the email and phone of a user
+type VerificationService interface {
+	Status(ctx context.Context, userID uint) (*VerificationStatus, error)
+	Send(ctx context.Context, userID uint, channel string) (*VerificationSent, error)
+	Verify(ctx context.Context, userID uint, channel string, req VerifyRequest) error
+	// SetPhone sets the phone number while it is not verified yet, e.g. for
+	// accounts created through an OIDC login
+	SetPhone(ctx context.Context, userID uint, req SetPhoneRequest) (*VerificationStatus, error)
+}
+
+type verificationService struct {
+	uow              repository.UnitOfWork
+	userRepo         repository.UserRepository
+	verificationRepo repository.VerificationRepository
+	mailer           notify.Mailer
+	sms              notify.SMSSender
+	cfg              config.AuthConfig
+}
+
+func NewVerificationService(
+	uow repository.UnitOfWork,
+	userRepo repository.UserRepository,
+	verificationRepo repository.VerificationRepository,
+	mailer notify.Mailer,
+	sms notify.SMSSender,
+	cfg config.AuthConfig,
+) VerificationService {
+	return &verificationService{
+		uow:              uow,
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
+		sms:              sms,
+		cfg:              cfg,
+	}
+}
+
+func (s *verificationService) Status(ctx context.Context, userID uint) (*VerificationStatus, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrUserNotFound, nil)
+	}
+	return toVerificationStatus(user), nil
+}
+
+func (s *verificationService) SetPhone(ctx context.Context, userID uint, req SetPhoneRequest) (*VerificationStatus, error) {
+	phone := strings.TrimSpace(req.NoTelp)
+	if phone == "" || len(phone) > 20 || strings.HasPrefix(phone, models.PhonePlaceholderPrefix) {
+		return nil, ErrInvalidPhone
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrUserNotFound, nil)
+	}
+	if user.PhoneVerifiedAt != nil {
+		return nil, ErrAlreadyVerified
+	}
+	if phone == user.NoTelp {
+		return toVerificationStatus(user), nil
+	}
+	existing, err := s.userRepo.FindByPhone(ctx, phone)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && existing.ID != user.ID {
+		return nil, ErrPhoneTaken
+	}
+	// Kode yang sudah terkirim terikat ke nomor lama (lihat hashOTP), jadi otomatis tidak berlaku
+	user.NoTelp = phone
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return toVerificationStatus(user), nil
+}
+
+func toVerificationStatus(user *models.User) *VerificationStatus {
+	status := &VerificationStatus{
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		PhoneVerified: user.PhoneVerifiedAt != nil,
+	}
+	if user.HasPhone() {
+		status.NoTelp = user.NoTelp
+	}
+	return status
+}
+
+func (s *verificationService) Send(ctx context.Context, userID uint, channel string) (*VerificationSent, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperr.FromDB(err, ErrUserNotFound, nil)
+	}
+	target, verified, err := channelTarget(user, channel)
+	if err != nil {
+		return nil, err
+	}
+	if verified {
+		return nil, ErrAlreadyVerified
+	}
+
+	// ====== Throttle: jeda antar kirim & batas per jam ======
+	now := time.Now()
+	last, err := s.verificationRepo.FindActive(ctx, userID, channel)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		if wait := last.CreatedAt.Add(s.cfg.OTPResendInterval).Sub(now); wait > 0 {
+			return nil, &ThrottleError{RetryAfter: wait}
+		}
+	}
+	sent, err := s.verificationRepo.CountSince(ctx, userID, channel, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if sent >= int64(s.cfg.OTPMaxPerHour) {
+		return nil, &ThrottleError{RetryAfter: time.Hour}
+	}
+
+	code, err := utils.GenerateOTP(otpDigits)
+	if err != nil {
+		return nil, err
+	}
+	// Kode lama otomatis hangus saat kode baru dikirim
+	err = s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Verification.InvalidateByChannel(ctx, userID, channel, now); err != nil {
+			return err
+		}
+		return repos.Verification.Create(ctx, &models.VerificationCode{
+			IDUser:    userID,
+			Channel:   channel,
+			Target:    target,
+			CodeHash:  hashOTP(target, code),
+			ExpiresAt: now.Add(s.cfg.OTPTTL),
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deliver(ctx, user, channel, target, code); err != nil {
+		return nil, fmt.Errorf("gagal mengirim kode verifikasi: %w", err)
+	}
+
+	return &VerificationSent{
+		Channel:     channel,
+		Target:      maskTarget(channel, target),
+		ExpiresIn:   int64(s.cfg.OTPTTL.Seconds()),
+		ResendAfter: int64(s.cfg.OTPResendInterval.Seconds()),
+	}, nil
+}
+
+func (s *verificationService) Verify(ctx context.Context, userID uint, channel string, req VerifyRequest) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return apperr.FromDB(err, ErrUserNotFound, nil)
+	}
+	target, verified, err := channelTarget(user, channel)
+	if err != nil {
+		return err
+	}
+	if verified {
+		return ErrAlreadyVerified
+	}
+
+	active, err := s.verificationRepo.FindActive(ctx, userID, channel)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidOTP
+		}
+		return err
+	}
+	now := time.Now()
+	if now.After(active.ExpiresAt) || active.Target != target {
+		return ErrInvalidOTP
+	}
+	// Jatah percobaan diambil sebelum kode dibandingkan, dengan update
+	// bersyarat, jadi tebakan paralel tetap dibatasi OTPMaxAttempts. Dicatat
+	// di luar tx supaya percobaan salah tetap terhitung.
+	ok, err := s.verificationRepo.ClaimAttempt(ctx, active.ID, s.cfg.OTPMaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOTPTooManyAttempts
+	}
+	if subtle.ConstantTimeCompare([]byte(active.CodeHash), []byte(hashOTP(target, req.Code))) != 1 {
+		return ErrInvalidOTP
+	}
+
+	return s.uow.WithTx(ctx, func(repos *repository.Repositories) error {
+		ok, err := repos.Verification.MarkUsed(ctx, active.ID, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidOTP
+		}
+		if channel == models.VerificationEmail {
+			user.EmailVerifiedAt = &now
+		} else {
+			user.PhoneVerifiedAt = &now
+		}
+		user.UpdatedAt = now
+		return repos.User.Update(ctx, user)
+	})
+}
+
+func (s *verificationService) deliver(ctx context.Context, user *models.User, channel, target, code string) error {
+	minutes := int(s.cfg.OTPTTL.Minutes())
+	if channel == models.VerificationEmail {
+		return s.mailer.Send(ctx, notify.Message{
+			To:      target,
+			Subject: "Kode verifikasi email",
+			Body: fmt.Sprintf(
+				"Halo %s,\n\nKode verifikasi email Anda: %s\nKode berlaku %d menit. Jangan berikan kode ini kepada siapa pun.\n",
+				user.Nama, code, minutes,
+			),
+		})
+	}
+	return s.sms.Send(ctx, notify.SMS{
+		To:   target,
+		Body: fmt.Sprintf("Kode verifikasi FinalTask: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.", code, minutes),
+	})
+}
+
+// ensureVerified menolak aksi jual/beli dari akun yang belum terverifikasi
+func ensureVerified(ctx context.Context, users repository.UserRepository, userID uint) error {
+	user, err := users.FindByID(ctx, userID)
+	if err != nil {
+		return apperr.FromDB(err, ErrUserNotFound, nil)
+	}
+	if !user.IsVerified() {
+		return ErrAccountNotVerified
+	}
+	return nil
+}
+
+func channelTarget(user *models.User, channel string) (target string, verified bool, err error) {
+	switch channel {
+	case models.VerificationEmail:
+		return user.Email, user.EmailVerifiedAt != nil, nil
+	case models.VerificationPhone:
+		if !user.HasPhone() {
+			return "", false, ErrPhoneMissing
+		}
+		return user.NoTelp, user.PhoneVerifiedAt != nil, nil
+	default:
+		return "", false, ErrUnknownChannel
+	}
+}
+
+// hashOTP mengikat kode ke alamat tujuannya sebelum di-hash
+func hashOTP(target, code string) string {
+	return utils.HashToken(target + ":" + code)
+}
+
+func maskTarget(channel, target string) string {
+	if channel == models.VerificationEmail {
+		at := strings.IndexByte(target, '@')
+		if at <= 1 {
+			return target
+		}
+		return target[:1] + strings.Repeat("*", at-1) + target[at:]
+	}
+	if len(target) <= 4 {
+		return target
+	}
+	return strings.Repeat("*", len(target)-4) + target[len(target)-4:]
+}
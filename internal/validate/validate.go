@@ -0,0 +1,313 @@
+// Package validate checks request DTOs against their `validate` struct tags
+// before a service runs. Rules are comma separated and checked in order;
+// the first failing rule of a field is reported:
+//
+//	required      not the zero value (strings: not blank, slices: not empty)
+//	omitempty     skip the other rules when the field is empty
+//	min=N, max=N  string length, number value or slice length
+//	email         a plain address such as budi@example.com
+//	numeric       a string of digits only, e.g. id_provinsi "31"
+//	phone         8-15 digits, optionally starting with +
+//	date          YYYY-MM-DD
+//	oneof=a b     one of the listed values
+//	dive          validate every element of a slice: rules after dive apply
+//	              to each element, struct elements are walked too
+//
+// Field names in the report are the json names, e.g. items[0].kuantitas.
+// Tags are parsed once per type; Register checks them at startup.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"FinalTask/internal/apperr"
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+
+// field is one tagged struct field with its rules already parsed
+type field struct {
+	index     int
+	name      string // json name
+	rules     []rule
+	omitEmpty bool
+	dive      bool
+	elem      *field // rules after dive, checked per element
+}
+
+type rule struct {
+	name    string
+	limit   float64  // min, max
+	allowed []string // oneof
+}
+
+// parsed caches the fields of every struct type seen, keyed by reflect.Type
+var parsed sync.Map
+
+// Register parses the tags of every given struct (or pointer to struct) up
+// front, so a malformed tag fails startup instead of the first request that
+// uses it. All problems are reported at once.
+func Register(values ...interface{}) error {
+	var errs []error
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			errs = append(errs, fmt.Errorf("validate: %T bukan struct", v))
+			continue
+		}
+		if _, err := fieldsOf(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Struct validates v (a struct or pointer to struct). It returns nil or a
+// VALIDATION_FAILED *apperr.Error whose details list every invalid field.
+// A malformed tag is returned as a plain error (500), never a panic.
+func Struct(v interface{}) error {
+	var errs []apperr.FieldError
+	if err := walk(reflect.Indirect(reflect.ValueOf(v)), "", &errs); err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apperr.Validation("data yang dikirim tidak valid").WithDetails(errs)
+}
+
+func walk(v reflect.Value, prefix string, errs *[]apperr.FieldError) error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	fields, err := fieldsOf(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		name := prefix + f.name
+		value := v.Field(f.index)
+		if msg := check(value, f); msg != "" {
+			*errs = append(*errs, apperr.FieldError{Field: name, Message: msg})
+			continue
+		}
+		if f.dive && value.Kind() == reflect.Slice {
+			for j := 0; j < value.Len(); j++ {
+				elemName := fmt.Sprintf("%s[%d]", name, j)
+				if msg := check(value.Index(j), *f.elem); msg != "" {
+					*errs = append(*errs, apperr.FieldError{Field: elemName, Message: msg})
+					continue
+				}
+				if err := walk(reflect.Indirect(value.Index(j)), elemName+".", errs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fieldsOf parses the validate tags of struct type t once; dive also parses
+// the element struct so Register catches errors there too
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if cached, ok := parsed.Load(t); ok {
+		return cached.([]field), nil
+	}
+	var fields []field
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		f, err := parseField(sf, tag)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("validate: %s.%s: %w", t.Name(), sf.Name, err))
+			continue
+		}
+		f.index = i
+		if f.dive {
+			elem := sf.Type.Elem()
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.Struct {
+				if _, err := fieldsOf(elem); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		fields = append(fields, f)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	parsed.Store(t, fields)
+	return fields, nil
+}
+
+func parseField(sf reflect.StructField, tag string) (field, error) {
+	f := field{name: jsonName(sf)}
+	cur := &f // setelah dive, rule berlaku untuk tiap elemen
+	for _, r := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(r, "=")
+		switch name {
+		case "required", "email", "numeric", "phone", "date":
+			cur.rules = append(cur.rules, rule{name: name})
+		case "min", "max":
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return field{}, fmt.Errorf("parameter %q tidak valid", r)
+			}
+			cur.rules = append(cur.rules, rule{name: name, limit: limit})
+		case "oneof":
+			allowed := strings.Fields(param)
+			if len(allowed) == 0 {
+				return field{}, fmt.Errorf("parameter %q tidak valid", r)
+			}
+			cur.rules = append(cur.rules, rule{name: name, allowed: allowed})
+		case "omitempty":
+			cur.omitEmpty = true
+		case "dive":
+			if f.dive || sf.Type.Kind() != reflect.Slice {
+				return field{}, fmt.Errorf("dive hanya sekali dan hanya untuk slice, bukan %s", sf.Type)
+			}
+			f.dive = true
+			f.elem = &field{}
+			cur = f.elem
+		default:
+			return field{}, fmt.Errorf("rule %q tidak dikenal", name)
+		}
+	}
+	return f, nil
+}
+
+// check returns the message of the first failing rule, "" if all pass
+func check(v reflect.Value, f field) string {
+	if f.omitEmpty && isEmpty(v) {
+		return ""
+	}
+	for _, r := range f.rules {
+		switch r.name {
+		case "required":
+			if isEmpty(v) {
+				return "wajib diisi"
+			}
+		case "min", "max":
+			if msg := checkBound(v, r.name, r.limit); msg != "" {
+				return msg
+			}
+		case "email":
+			if !isEmail(v.String()) {
+				return "format email tidak valid"
+			}
+		case "numeric":
+			if !isDigits(v.String()) {
+				return "harus berupa angka"
+			}
+		case "phone":
+			if !phonePattern.MatchString(v.String()) {
+				return "nomor telepon harus 8-15 digit"
+			}
+		case "date":
+			if _, err := time.Parse("2006-01-02", v.String()); err != nil {
+				return "format tanggal harus YYYY-MM-DD"
+			}
+		case "oneof":
+			if !contains(r.allowed, fmt.Sprint(v.Interface())) {
+				return "harus salah satu dari: " + strings.Join(r.allowed, ", ")
+			}
+		}
+	}
+	return ""
+}
+
+func checkBound(v reflect.Value, rule string, limit float64) string {
+	var n float64
+	unit := ""
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " karakter"
+	case reflect.Slice, reflect.Map:
+		n, unit = float64(v.Len()), " item"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		return ""
+	}
+	if rule == "min" && n < limit {
+		return "minimal " + strconv.FormatFloat(limit, 'f', -1, 64) + unit
+	}
+	if rule == "max" && n > limit {
+		return "maksimal " + strconv.FormatFloat(limit, 'f', -1, 64) + unit
+	}
+	return ""
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// isEmail menolak nama tampilan ("Budi <b@x.id>") dan domain tanpa titik
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return false
+	}
+	_, domain, _ := strings.Cut(s, "@")
+	return strings.Contains(domain, ".")
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
@@ -0,0 +1,79 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"FinalTask/internal/apperr"
+)
+
+type item struct {
+	Kuantitas int `json:"kuantitas" validate:"min=1"`
+}
+
+type order struct {
+	Email string   `json:"email" validate:"required,email"`
+	Items []item   `json:"items" validate:"required,max=2,dive"`
+	Tags  []string `json:"tags" validate:"omitempty,dive,oneof=a b"`
+}
+
+type badMax struct {
+	Nama string `json:"nama" validate:"max=abc"`
+}
+
+type badRule struct {
+	Nama string `json:"nama" validate:"requird"`
+}
+
+type badElem struct {
+	Items []badRule `json:"items" validate:"dive"`
+}
+
+func TestStruct(t *testing.T) {
+	err := Struct(order{Email: "budi", Items: []item{{Kuantitas: 1}, {Kuantitas: 0}}, Tags: []string{"a", "c"}})
+	e, ok := apperr.As(err)
+	if !ok {
+		t.Fatalf("err = %v, want *apperr.Error", err)
+	}
+	details, _ := e.Details.([]apperr.FieldError)
+	var fields []string
+	for _, d := range details {
+		fields = append(fields, d.Field)
+	}
+	if got, want := strings.Join(fields, " "), "email items[1].kuantitas tags[1]"; got != want {
+		t.Errorf("invalid fields = %q, want %q", got, want)
+	}
+	if err := Struct(&order{Email: "budi@example.com", Items: []item{{Kuantitas: 2}}}); err != nil {
+		t.Errorf("valid order: %v", err)
+	}
+}
+
+// A malformed tag is an error, not a panic, and Register finds it before any
+// request does, including inside dive elements
+func TestMalformedTags(t *testing.T) {
+	for _, v := range []interface{}{badMax{}, badRule{}, badElem{}} {
+		err := Struct(v)
+		if err == nil {
+			t.Errorf("Struct(%T) = nil, want tag error", v)
+			continue
+		}
+		if _, ok := apperr.As(err); ok {
+			t.Errorf("Struct(%T) = %v, want a plain (500) error", v, err)
+		}
+	}
+	err := Register(order{}, &badMax{}, badElem{})
+	if err == nil {
+		t.Fatal("Register = nil, want errors for badMax and badElem")
+	}
+	for _, want := range []string{"badMax.Nama", "badRule.Nama"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Register error %q does not mention %s", err, want)
+		}
+	}
+	if err := Register(order{}); err != nil {
+		t.Errorf("Register(order) = %v", err)
+	}
+	if err := Register("x"); err == nil {
+		t.Error("Register(string) = nil, want error")
+	}
+}
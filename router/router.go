@@ -2,40 +2,85 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 
+	"FinalTask/config"
 	"FinalTask/internal/handler"
+	"FinalTask/internal/middleware"
+	"FinalTask/internal/notify"
+	"FinalTask/internal/oidc"
 	"FinalTask/internal/repository"
 	"FinalTask/internal/service"
+	"FinalTask/utils"
 )
 
-func SetupRoutes(app *fiber.App) {
+func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB, jwtKeys *utils.JWTKeys, mailer notify.Mailer, sms notify.SMSSender) {
 	// ===== Repository Layer =====
-	userRepo := repository.NewUserRepository()
-	storeRepo := repository.NewStoreRepository()
-	addressRepo := repository.NewAddressRepository()
-	categoryRepo := repository.NewCategoryRepository()
-	productRepo := repository.NewProductRepository()
-	trxRepo := repository.NewTransactionRepository()
+	userRepo := repository.NewUserRepository(db)
+	storeRepo := repository.NewStoreRepository(db)
+	addressRepo := repository.NewAddressRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	productRepo := repository.NewProductRepository(db)
+	trxRepo := repository.NewTransactionRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	verificationRepo := repository.NewVerificationRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	challengeRepo := repository.NewLoginChallengeRepository(db)
+	settingRepo := repository.NewSettingRepository(db)
+	oidcStateRepo := repository.NewOIDCStateRepository(db)
+	uow := repository.NewUnitOfWork(db)
+
+	// Provider OIDC ("Login with ..."), discovery baru dilakukan saat login pertama
+	oidcProviders := oidc.NewRegistry(cfg.OIDC.Providers)
 
 	// ===== Service Layer =====
-	authService := service.NewAuthService(userRepo, storeRepo) // contoh: auth butuh user & store
-	userService := service.NewUserService(userRepo)
+	verificationService := service.NewVerificationService(uow, userRepo, verificationRepo, mailer, sms, cfg.Auth)
+	authService := service.NewAuthService(uow, userRepo, refreshTokenRepo, loginAttemptRepo, challengeRepo, settingRepo, oidcStateRepo, oidcProviders, verificationService, mailer, jwtKeys, cfg.JWT, cfg.Auth, cfg.OIDC) // register butuh user & store dalam satu tx
+	userService := service.NewUserService(userRepo, cfg.Region.BaseURL)
 	storeService := service.NewStoreService(storeRepo)
-	addressService := service.NewAddressService(addressRepo)
+	addressService := service.NewAddressService(addressRepo, cfg.Region.BaseURL)
 	categoryService := service.NewCategoryService(categoryRepo)
-	productService := service.NewProductService(productRepo, storeRepo, categoryRepo)
-	trxService := service.NewTransactionService(trxRepo, productRepo)
+	productService := service.NewProductService(uow, productRepo, storeRepo, categoryRepo, userRepo, cfg.App.UploadDir)
+	trxService := service.NewTransactionService(uow, trxRepo, userRepo, storeRepo)
+	sessionService := service.NewSessionService(uow, sessionRepo, userRepo)
+	roleService := service.NewRoleService(uow, roleRepo)
+	apiKeyService := service.NewAPIKeyService(uow, apiKeyRepo, storeRepo)
+	twoFactorService := service.NewTwoFactorService(uow, userRepo, recoveryCodeRepo, settingRepo, cfg.Auth)
+
+	// ===== Middleware =====
+	auth := middleware.JWTProtected(jwtKeys, sessionRepo)
+	// Route produk & transaksi juga menerima API key toko (integrasi ERP)
+	integrationAuth := middleware.JWTOrAPIKey(auth, apiKeyService)
+
+	// ===== Static Files =====
+	app.Static("/uploads", cfg.App.UploadDir)
+
+	// ===== Health Probes (di luar /api/v1) =====
+	handler.NewHealthHandler(app, db, !cfg.DB.AutoMigrate)
+
+	// ===== JWKS untuk service lain yang memverifikasi access token =====
+	handler.NewJWKSHandler(app, jwtKeys)
 
 	// ===== Handler Layer =====
 	api := app.Group("/api/v1")
 
-	handler.NewAuthHandler(api, authService)
-	handler.NewUserHandler(api, userService)
-	handler.NewStoreHandler(api, storeService)
-	handler.NewAddressHandler(api, addressService)
-	handler.NewCategoryHandler(api, categoryService)
-	handler.NewProductHandler(api, productService)
-	handler.NewTransactionHandler(api, trxService)
+	handler.NewAuthHandler(api, auth, authService)
+	handler.NewOIDCHandler(api, authService)
+	handler.NewUserHandler(api, auth, userService)
+	handler.NewStoreHandler(api, auth, storeService)
+	handler.NewAddressHandler(api, auth, addressService)
+	handler.NewCategoryHandler(api, auth, categoryService)
+	handler.NewProductHandler(api, integrationAuth, productService)
+	handler.NewTransactionHandler(api, auth, integrationAuth, trxService)
+	handler.NewSessionHandler(api, auth, sessionService)
+	handler.NewVerificationHandler(api, auth, verificationService)
+	handler.NewRoleHandler(api, auth, roleService)
+	handler.NewAPIKeyHandler(api, auth, apiKeyService)
+	handler.NewTwoFactorHandler(api, auth, twoFactorService)
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("API FinalTask Rakamin is running 🚀")
@@ -1,7 +1,16 @@
 // File: utils/hash.go
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 // HashPassword menghasilkan hash bcrypt dari plaintext password
 func HashPassword(password string) (string, error) {
@@ -14,3 +23,30 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// GenerateOpaqueToken membuat token acak (untuk refresh token dll.) beserta
+// hash SHA-256-nya. Hanya hash yang disimpan di database.
+func GenerateOpaqueToken() (plain string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	plain = base64.RawURLEncoding.EncodeToString(buf)
+	return plain, HashToken(plain), nil
+}
+
+// HashToken menghasilkan hash SHA-256 (hex) dari token opaque
+func HashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateOTP membuat kode numerik acak sepanjang digits (mis. 6 digit)
+func GenerateOTP(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
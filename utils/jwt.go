@@ -2,20 +2,95 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"FinalTask/config"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
-// GenerateJWT membuat token JWT dengan klaim user_id dan is_admin
-func GenerateJWT(userID uint, isAdmin bool) (string, error) {
+// AccessClaims is what an access token says about its bearer
+type AccessClaims struct {
+	UserID      uint
+	SessionID   string
+	Roles       []string
+	Permissions []string
+}
+
+var (
+	ErrUnknownKey    = errors.New("kid token tidak dikenal")
+	ErrWrongIssuer   = errors.New("issuer token tidak valid")
+	ErrWrongAudience = errors.New("audience token tidak valid")
+)
+
+// JWTKeys holds the key that signs new access tokens and every key that is
+// still accepted when verifying them, indexed by kid
+type JWTKeys struct {
+	cfg     config.JWTConfig
+	signer  *jwtKey
+	keys    map[string]*jwtKey
+	order   []string // urutan kid untuk JWKS
+	methods []string
+}
+
+// GenerateJWT membuat token JWT dengan header kid dan klaim user_id, sid
+// (ID sesi), roles, perms, iss, aud serta jti unik per token
+func (k *JWTKeys) GenerateJWT(c AccessClaims) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"user_id":  userID,
-		"is_admin": isAdmin,
-		"exp":      time.Now().Add(72 * time.Hour).Unix(),
+		"user_id": c.UserID,
+		"sid":     c.SessionID,
+		"roles":   nonNil(c.Roles),
+		"perms":   nonNil(c.Permissions),
+		"iss":     k.cfg.Issuer,
+		"aud":     k.cfg.Audience,
+		"jti":     uuid.NewString(),
+		"iat":     now.Unix(),
+		"exp":     now.Add(k.cfg.AccessTTL).Unix(),
+	}
+	token := jwt.NewWithClaims(k.signer.method, claims)
+	token.Header["kid"] = k.signer.id
+	return token.SignedString(k.signer.private)
+}
+
+// ParseJWT memverifikasi tanda tangan (kunci dipilih dari kid, algoritmanya
+// harus cocok dengan kunci), masa berlaku, issuer dan audience
+func (k *JWTKeys) ParseJWT(tokenStr string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods(k.methods))
+	token, err := parser.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if t.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("algoritma %s tidak cocok dengan kunci %s", t.Method.Alg(), kid)
+		}
+		return key.public, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("token tidak valid")
+	}
+	if !claims.VerifyIssuer(k.cfg.Issuer, true) {
+		return nil, ErrWrongIssuer
+	}
+	if !claims.VerifyAudience(k.cfg.Audience, true) {
+		return nil, ErrWrongAudience
+	}
+	return claims, nil
+}
+
+// nonNil supaya klaim kosong diserialisasi sebagai [] bukan null
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(config.JwtSecret)
+	return list
 }
@@ -0,0 +1,242 @@
+// File: utils/jwt_keys.go
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"FinalTask/config"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// jwtKey is one signing/verification key. private is nil for keys that are
+// only kept to verify tokens issued before a rotation.
+type jwtKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+	jwk     *JWK // nil untuk HMAC, secret tidak pernah dipublikasikan
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the body of the JWKS endpoint
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadJWTKeys membaca kunci sesuai cfg.Algorithm. Kunci di PublicKeyFiles
+// dan JWT_SECRET (bila diisi) tetap diterima saat verifikasi, sehingga
+// rotasi kunci tidak membuat semua user logout.
+func LoadJWTKeys(cfg config.JWTConfig) (*JWTKeys, error) {
+	k := &JWTKeys{cfg: cfg, keys: map[string]*jwtKey{}}
+
+	if cfg.Secret != "" {
+		secret := []byte(cfg.Secret)
+		k.add(&jwtKey{
+			id:      "hs-" + HashToken("jwt-kid:" + cfg.Secret)[:16],
+			method:  jwt.SigningMethodHS256,
+			private: secret,
+			public:  secret,
+		})
+	}
+
+	switch cfg.Algorithm {
+	case config.JWTAlgHS256:
+		if cfg.Secret == "" {
+			return nil, errors.New("JWT_SECRET wajib diisi untuk HS256")
+		}
+		k.signer = k.keys[k.order[0]]
+	case config.JWTAlgRS256, config.JWTAlgEdDSA:
+		raw, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("baca JWT_PRIVATE_KEY_FILE: %w", err)
+		}
+		signer, err := parsePrivateKeyPEM(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.PrivateKeyFile, err)
+		}
+		key, err := newAsymmetricKey(signer.Public(), signer)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.PrivateKeyFile, err)
+		}
+		if key.method.Alg() != cfg.Algorithm {
+			return nil, fmt.Errorf("%s berisi kunci %s, bukan %s", cfg.PrivateKeyFile, key.method.Alg(), cfg.Algorithm)
+		}
+		k.signer = k.add(key)
+	default:
+		return nil, fmt.Errorf("JWT_ALGORITHM %q tidak dikenal", cfg.Algorithm)
+	}
+
+	for _, path := range cfg.PublicKeyFiles {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("baca JWT_PUBLIC_KEY_FILES: %w", err)
+		}
+		pub, err := parsePublicKeyPEM(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		key, err := newAsymmetricKey(pub, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		k.add(key)
+	}
+	return k, nil
+}
+
+// add mendaftarkan kunci; kid yang sama (file dobel) cukup sekali
+func (k *JWTKeys) add(key *jwtKey) *jwtKey {
+	if existing, ok := k.keys[key.id]; ok {
+		if existing.private == nil {
+			existing.private = key.private
+		}
+		return existing
+	}
+	k.keys[key.id] = key
+	k.order = append(k.order, key.id)
+	for _, m := range k.methods {
+		if m == key.method.Alg() {
+			return key
+		}
+	}
+	k.methods = append(k.methods, key.method.Alg())
+	return key
+}
+
+// JWKS returns the public verification keys, the signing key first.
+// HMAC secrets are never included.
+func (k *JWTKeys) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if k.signer.jwk != nil {
+		set.Keys = append(set.Keys, *k.signer.jwk)
+	}
+	for _, id := range k.order {
+		if key := k.keys[id]; key != k.signer && key.jwk != nil {
+			set.Keys = append(set.Keys, *key.jwk)
+		}
+	}
+	return set
+}
+
+// String ringkasan untuk log startup (tanpa materi kunci)
+func (k *JWTKeys) String() string {
+	return fmt.Sprintf("%s kid=%s, %d kunci verifikasi", k.signer.method.Alg(), k.signer.id, len(k.keys))
+}
+
+// GenerateJWTKeyPEM membuat pasangan kunci baru untuk RS256 (RSA 3072)
+// atau EdDSA (Ed25519): kunci privat PKCS#8 dan kunci publik PKIX
+func GenerateJWTKeyPEM(alg string) (privatePEM, publicPEM []byte, err error) {
+	var signer crypto.Signer
+	switch alg {
+	case config.JWTAlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 3072)
+	case config.JWTAlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, nil, fmt.Errorf("algoritma %q tidak didukung (RS256, EdDSA)", alg)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	privatePEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	return privatePEM, publicPEM, nil
+}
+
+// newAsymmetricKey menentukan algoritma dari tipe kunci publik dan memberi
+// kid berupa JWK thumbprint (RFC 7638), jadi kid stabil tanpa konfigurasi
+func newAsymmetricKey(pub crypto.PublicKey, private crypto.Signer) (*jwtKey, error) {
+	key := &jwtKey{public: pub}
+	if private != nil {
+		key.private = private
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	var thumbprintInput string
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		if p.N.BitLen() < 2048 {
+			return nil, errors.New("kunci RSA minimal 2048 bit")
+		}
+		key.method = jwt.SigningMethodRS256
+		n, e := b64(p.N.Bytes()), b64(big.NewInt(int64(p.E)).Bytes())
+		key.jwk = &JWK{Kty: "RSA", Use: "sig", Alg: config.JWTAlgRS256, N: n, E: e}
+		thumbprintInput = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, e, n)
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+		x := b64(p)
+		key.jwk = &JWK{Kty: "OKP", Use: "sig", Alg: config.JWTAlgEdDSA, Crv: "Ed25519", X: x}
+		thumbprintInput = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, x)
+	default:
+		return nil, fmt.Errorf("tipe kunci %T tidak didukung (RSA atau Ed25519)", pub)
+	}
+
+	sum := sha256.Sum256([]byte(thumbprintInput))
+	key.id = b64(sum[:])
+	key.jwk.Kid = key.id
+	return key, nil
+}
+
+func parsePrivateKeyPEM(raw []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("bukan file PEM")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("tipe kunci %T tidak didukung", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("format kunci privat tidak didukung (PKCS#8 atau PKCS#1)")
+}
+
+func parsePublicKeyPEM(raw []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("bukan file PEM")
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("format kunci publik tidak didukung (PKIX atau PKCS#1)")
+}
@@ -0,0 +1,121 @@
+// File: utils/totp.go
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameter TOTP (RFC 6238) yang didukung semua aplikasi authenticator
+const (
+	totpDigits = 6
+	totpPeriod = 30 // detik
+	totpSkew   = 1  // toleransi ±1 periode untuk jam yang sedikit meleset
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret membuat secret acak 160-bit dalam base32
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// TOTPProvisioningURI membuat URI otpauth:// yang dijadikan QR code oleh
+// frontend dan dipindai aplikasi authenticator
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// TOTPCode menghitung kode untuk waktu t
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("secret TOTP tidak valid: %w", err)
+	}
+	return hotp(key, uint64(t.Unix()/totpPeriod)), nil
+}
+
+// ValidateTOTP mencocokkan code dengan periode sekarang ±totpSkew dan
+// mengembalikan nomor periode yang cocok. Periode <= lastStep ditolak
+// supaya kode yang sama tidak bisa dipakai dua kali.
+func ValidateTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(step))), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// hotp implements RFC 4226 with dynamic truncation
+func hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// GenerateRecoveryCode membuat kode pemulihan sekali pakai, mis. "k7q2-m9xd-4fpa"
+func GenerateRecoveryCode() (string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789" // tanpa 0/o, 1/l/i
+	// Byte >= 248 dibuang supaya setiap huruf sama peluangnya (248 = 8×31)
+	limit := byte(256 / len(alphabet) * len(alphabet))
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for n := 0; n < 12; {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		if buf[0] >= limit {
+			continue
+		}
+		if n > 0 && n%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(alphabet[int(buf[0])%len(alphabet)])
+		n++
+	}
+	return b.String(), nil
+}
+
+// NormalizeRecoveryCode membuang spasi/tanda minus dan huruf besar agar
+// kode yang diketik ulang tetap cocok dengan hash-nya
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}